@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"google.golang.org/grpc"
+)
+
+// instance is the grpc server for one config. Each config listens on its own
+// address, with its own matches and sinks.
+type instance struct {
+	cfg    servers.Config
+	lis    net.Listener
+	grpc   *grpc.Server
+	trace  *servers.TraceServer
+	audit  *servers.AuditLog
+	logs   *servers.LogExporter
+	report *servers.Report
+}
+
+func newInstance(cfg servers.Config, health *servers.Health, metrics *servers.Metrics) (*instance, error) {
+	g, version, err := loadGroups(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Schema.URL == "" {
+		cfg.Schema.URL = version
+	}
+
+	i := &instance{cfg: cfg}
+	opts := []servers.Option{servers.WithMetrics(metrics), servers.WithGroupsVersion(version)}
+	if cfg.Audit.Path != "" {
+		i.audit, err = servers.NewAuditLog(cfg.Audit)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, servers.WithSink(i.audit))
+		metrics.WatchAuditLog(i.audit)
+	}
+	if cfg.LogExport.Endpoint != "" {
+		i.logs, err = servers.NewLogExporter(cfg.LogExport)
+		if err != nil {
+			i.close()
+			return nil, err
+		}
+		opts = append(opts, servers.WithSink(i.logs))
+	}
+	if cfg.Report.Path != "" {
+		i.report, err = servers.NewReport(cfg.Report)
+		if err != nil {
+			i.close()
+			return nil, err
+		}
+		opts = append(opts, servers.WithSink(i.report))
+	}
+
+	i.lis, err = listen(cfg.ServerAddress, cfg.SocketMode)
+	if err != nil {
+		i.close()
+		return nil, err
+	}
+	i.lis = servers.LimitListener(i.lis, cfg)
+	i.grpc = grpc.NewServer(servers.ServerOptions(cfg)...)
+	i.trace, err = servers.RegisterAll(i.grpc, cfg, g, opts...)
+	if err != nil {
+		i.discard()
+		return nil, err
+	}
+	health.Register(i.grpc)
+	return i, nil
+}
+
+func (i *instance) serve(errs chan<- error) {
+	// Loading the groups can take a while, the warmup starts from serving.
+	i.trace.StartWarmup()
+	go func() {
+		errs <- i.grpc.Serve(i.lis)
+	}()
+	slog.Info("starting server", "address", i.cfg.ServerAddress)
+	if i.cfg.Warmup > 0 {
+		slog.Info("not enforcing until warmup ends", "address", i.cfg.ServerAddress, "warmup", i.cfg.Warmup)
+	}
+}
+
+// stop waits for in flight exports, up to timeout, then reports and closes the
+// sinks. Exports still running after a timeout have their violations dropped.
+func (i *instance) stop(timeout time.Duration) {
+	gracefulStop(i.grpc, timeout)
+	i.trace.LogUnused()
+	if i.report != nil {
+		if err := i.report.WriteFile(); err != nil {
+			slog.Error("failed to write report", "path", i.cfg.Report.Path, "error", err)
+		}
+	}
+	i.close()
+}
+
+// discard closes the listener and sinks of an instance that never served.
+func (i *instance) discard() {
+	i.lis.Close()
+	i.close()
+}
+
+func (i *instance) close() {
+	if i.audit != nil {
+		if err := i.audit.Close(); err != nil {
+			slog.Error("failed to close audit log", "error", err)
+		}
+	}
+	if i.logs != nil {
+		if err := i.logs.Close(); err != nil {
+			slog.Error("failed to close log exporter", "error", err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listen listens on a tcp address, or a unix socket for unix:// addresses. A
+// stale socket file is removed first, the listener removes it again when
+// closed. The socket is created with mode permissions, 0660 by default.
+func listen(address, mode string) (net.Listener, error) {
+	file, ok := strings.CutPrefix(address, "unix://")
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	perm := uint64(0o660)
+	if mode != "" {
+		var err error
+		if perm, err = strconv.ParseUint(mode, 8, 32); err != nil {
+			return nil, fmt.Errorf("invalid socket mode %q: %w", mode, err)
+		}
+	}
+	if info, err := os.Stat(file); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(file); err != nil {
+			return nil, err
+		}
+	}
+	// The umask keeps the socket from ever having more than the mode, unlike
+	// only changing it after it exists.
+	restore := umask(0o777 &^ int(perm))
+	lis, err := net.Listen("unix", file)
+	restore()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(file, os.FileMode(perm)); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenTCP(t *testing.T) {
+	lis, err := listen("127.0.0.1:0", "bad")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	assert.Equal(t, "tcp", lis.Addr().Network())
+}
+
+func TestListenUnix(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "checker.sock")
+
+	lis, err := listen("unix://"+file, "600")
+	require.NoError(t, err)
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, lis.Close())
+	_, err = os.Stat(file)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "checker.sock")
+	stale, err := net.Listen("unix", file)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	lis, err := listen("unix://"+file, "")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+}
+
+func TestListenKeepsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "checker.sock")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, err := listen("unix://"+file, "")
+	assert.Error(t, err)
+	assert.FileExists(t, file)
+}
+
+func TestListenInvalidMode(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "checker.sock")
+
+	_, err := listen("unix://"+file, "rw")
+	assert.ErrorContains(t, err, `invalid socket mode "rw"`)
+	assert.NoFileExists(t, file)
+}
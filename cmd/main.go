@@ -2,82 +2,345 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
-	pbLog "go.opentelemetry.io/proto/otlp/collector/logs/v1"
-	pbMetric "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
-	pbTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
 )
 
-var config = flag.String("cfg", "config.yaml", "The config file to use.")
+const (
+	fetchTimeout           = time.Minute
+	defaultShutdownTimeout = 10 * time.Second
+)
+
+// configFiles are the -cfg flags, each runs its own server.
+type configFiles []string
+
+func (c *configFiles) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *configFiles) Set(file string) error {
+	*c = append(*c, file)
+	return nil
+}
+
+var configs configFiles
 var oneshot = flag.Bool("one", false, "The server will only receive one message, and exit 100 if it any attributes are missing.")
 
+func init() {
+	flag.Var(&configs, "cfg", "The config file, or a directory of config files to merge, to use (default config.yaml). Repeat it to serve several configs, each on its own server_address.")
+}
+
 func main() {
 	flag.Parse()
+	if len(configs) == 0 {
+		configs = configFiles{"config.yaml"}
+	}
 
-	g, err := semconv.ParseGroups()
-	if err != nil {
-		slog.Error("failed to parse groups", "error", err)
-		return
+	cfgs := make([]servers.Config, 0, len(configs))
+	for _, file := range configs {
+		cfg, err := loadConfig(file)
+		if err != nil {
+			slog.Error("failed to unmarshal config", "file", file, "error", err)
+			return
+		}
+		if *oneshot {
+			cfg.OneShot = true
+			cfg.Signals.Trace.OneShot = nil
+			cfg.Signals.Metric.OneShot = nil
+			cfg.Signals.Log.OneShot = nil
+		}
+		cfgs = append(cfgs, cfg)
 	}
+	// The first config also sets up logging and the admin server.
+	cfg := cfgs[0]
 
-	cfg := servers.Config{}
+	level := &slog.LevelVar{}
+	level.Set(cfg.Level())
+	setupLogging(cfg, os.Stderr, level)
 
-	viper.SetConfigFile(*config)
-	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println(err)
-		viper.SetConfigType("yaml")
-		viper.ReadConfig(strings.NewReader(servers.DefaultConfig))
+	if flag.Arg(0) == "validate" {
+		os.Exit(validate(cfg, flag.Args()[1:]))
 	}
-	if err := viper.Unmarshal(&cfg); err != nil {
-		slog.Error("failed to unmarshal config", "error", err)
-		return
+
+	health := servers.NewHealth()
+	metrics := servers.NewMetrics()
+	mux := http.NewServeMux()
+	var admin *http.Server
+	var err error
+	if cfg.AdminAddress != "" {
+		health.Handle(mux)
+		mux.Handle("/metrics", metrics)
+		admin, err = startAdmin(cfg.AdminAddress, mux)
+		if err != nil {
+			slog.Error("failed to listen", "address", cfg.AdminAddress, "error", err)
+			return
+		}
+	}
+
+	instances := []*instance{}
+	for _, cfg := range cfgs {
+		i, err := newInstance(cfg, health, metrics)
+		if err != nil {
+			slog.Error("failed to start server", "address", cfg.ServerAddress, "error", err)
+			for _, i := range instances {
+				i.discard()
+			}
+			if admin != nil {
+				admin.Close()
+			}
+			os.Exit(1)
+		}
+		instances = append(instances, i)
+	}
+	mux.HandleFunc("/coverage", func(w http.ResponseWriter, r *http.Request) {
+		address := r.URL.Query().Get("address")
+		for _, i := range instances {
+			if address == "" || address == i.cfg.ServerAddress {
+				i.trace.ServeCoverage(w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	if cfg.AdminToken != "" {
+		traces := make([]*servers.TraceServer, 0, len(instances))
+		for _, i := range instances {
+			traces = append(traces, i.trace)
+		}
+		mux.Handle("/mode", servers.NewMode(cfg.AdminToken, level, traces...))
 	}
 
-	if *oneshot {
-		cfg.OneShot = true
+	serveErr := make(chan error, len(instances))
+	done := make(chan int, len(instances))
+	for _, i := range instances {
+		i.serve(serveErr)
+		go func(i *instance) {
+			done <- <-i.trace.Done()
+		}(i)
+	}
+	health.SetReady(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	code := 0
+	select {
+	case err := <-serveErr:
+		// The other instances still serve, they are stopped below.
+		slog.Error("failed to serve", "error", err)
+		code = 1
+	case <-ctx.Done():
+	case code = <-done:
 	}
 
-	lis, err := net.Listen("tcp", cfg.ServerAddress)
+	timeout := cfg.ShutdownTimeout
+	if timeout == 0 {
+		timeout = defaultShutdownTimeout
+	}
+	slog.Info("shutting down", "timeout", timeout)
+	health.SetReady(false)
+	var wg sync.WaitGroup
+	for _, i := range instances {
+		wg.Add(1)
+		go func(i *instance) {
+			defer wg.Done()
+			i.stop(timeout)
+		}(i)
+	}
+	wg.Wait()
+	if admin != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		admin.Shutdown(ctx)
+		cancel()
+	}
+	os.Exit(code)
+}
+
+func startAdmin(address string, handler http.Handler) (*http.Server, error) {
+	lis, err := net.Listen("tcp", address)
 	if err != nil {
-		slog.Error("failed to listen", "address", cfg.ServerAddress, "error", err)
-		return
+		return nil, err
 	}
+	srv := &http.Server{Handler: handler}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("failed to serve admin", "error", err)
+		}
+	}()
+	slog.Info("starting admin server", "address", address)
+	return srv, nil
+}
 
-	grpcServer := grpc.NewServer()
-	pbTrace.RegisterTraceServiceServer(grpcServer, servers.NewTraceService(cfg, g))
-	pbMetric.RegisterMetricsServiceServer(grpcServer, &metricServer{g: g})
-	pbLog.RegisterLogsServiceServer(grpcServer, &logServer{g: g})
+// gracefulStop stops accepting connections and waits for in flight exports to
+// finish, up to timeout, before closing any that remain.
+func gracefulStop(s *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("shutdown timed out, closing remaining connections")
+		s.Stop()
+	}
+}
 
-	slog.Info("starting server", "address", cfg.ServerAddress)
-	if err := grpcServer.Serve(lis); err != nil {
-		slog.Error("failed to serve", "error", err)
-		return
+// setupLogging replaces the default logger when the config or level need a
+// different handler, or the level can be changed from the admin server.
+func setupLogging(cfg servers.Config, w io.Writer, level slog.Leveler) {
+	opts := &slog.HandlerOptions{Level: level}
+	if len(cfg.LogKeys) > 0 {
+		opts.ReplaceAttr = servers.RenameKeys(cfg.LogKeys)
+	}
+	switch {
+	case cfg.LogFormat == servers.LogJSON:
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, opts)))
+	case opts.ReplaceAttr != nil || level.Level() != slog.LevelInfo || cfg.AdminToken != "":
+		slog.SetDefault(slog.New(slog.NewTextHandler(w, opts)))
 	}
 }
 
-type metricServer struct {
-	pbMetric.UnimplementedMetricsServiceServer
-	g map[string]semconv.Group
+func loadConfig(path string) (servers.Config, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return loadConfigDir(path)
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) && !errors.Is(err, fs.ErrNotExist) {
+			return servers.Config{}, readError(path, err)
+		}
+		slog.Info("config file not found, using the default config", "file", path)
+		v.SetConfigType("yaml")
+		v.ReadConfig(strings.NewReader(servers.DefaultConfig))
+	}
+	return unmarshal(v, path)
 }
 
-func (s *metricServer) Export(ctx context.Context, req *pbMetric.ExportMetricsServiceRequest) (*pbMetric.ExportMetricsServiceResponse, error) {
-	return nil, nil
+// readError names the file of an error reading a config. YAML errors have
+// their line, JSON syntax errors get their byte offset added.
+func readError(file string, err error) error {
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		return fmt.Errorf("%s: offset %d: %w", file, syntax.Offset, err)
+	}
+	return fmt.Errorf("%s: %w", file, err)
 }
 
-type logServer struct {
-	pbLog.UnimplementedLogsServiceServer
-	g map[string]semconv.Group
+// unmarshal decodes the config read from file. Unknown keys are an error,
+// rather than silently ignored, naming the file and the path of every key.
+func unmarshal(v *viper.Viper, file string) (servers.Config, error) {
+	cfg := servers.Config{}
+	md := mapstructure.Metadata{}
+	if err := v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) { dc.Metadata = &md }); err != nil {
+		return servers.Config{}, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(md.Unused) > 0 {
+		// Paths use the field names of keys without a tag, viper has already
+		// lower cased the keys themselves.
+		unknown := make([]string, len(md.Unused))
+		for i, key := range md.Unused {
+			unknown[i] = strings.ToLower(key)
+		}
+		sort.Strings(unknown)
+		return servers.Config{}, fmt.Errorf("%s: unknown keys %s", file, strings.Join(unknown, ", "))
+	}
+	return cfg, nil
 }
 
-func (s *logServer) Export(ctx context.Context, req *pbLog.ExportLogsServiceRequest) (*pbLog.ExportLogsServiceResponse, error) {
-	return nil, nil
+// loadConfigDir merges every yaml and json file in dir, in name order, into
+// one config.
+func loadConfigDir(dir string) (servers.Config, error) {
+	files := []string{}
+	for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+		matched, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return servers.Config{}, err
+		}
+		files = append(files, matched...)
+	}
+	if len(files) == 0 {
+		return servers.Config{}, fmt.Errorf("no config files in %s", dir)
+	}
+	sort.Strings(files)
+	fragments := make([]servers.Fragment, 0, len(files))
+	for _, file := range files {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return servers.Config{}, readError(file, err)
+		}
+		cfg, err := unmarshal(v, file)
+		if err != nil {
+			return servers.Config{}, err
+		}
+		fragments = append(fragments, servers.Fragment{Config: cfg, Keys: v.AllKeys()})
+	}
+	return servers.Merge(fragments...)
+}
+
+func loadGroups(cfg servers.Config) (map[string]semconv.Group, string, error) {
+	g, version, err := loadSemconv(cfg.Semconv)
+	if err != nil {
+		return nil, "", err
+	}
+	semconv.FilterDomains(g, cfg.Semconv.Domains, cfg.Semconv.ExcludeDomains)
+	if err := semconv.AddGroups(g, cfg.CustomGroups()...); err != nil {
+		return nil, "", err
+	}
+	if err := cfg.Validate(g); err != nil {
+		return nil, "", err
+	}
+	return g, version, nil
+}
+
+func loadSemconv(cfg servers.SemconvConfig) (map[string]semconv.Group, string, error) {
+	if cfg.Fetch {
+		cache := cfg.Cache
+		if cache == "" {
+			dir, err := os.UserCacheDir()
+			if err != nil {
+				dir = os.TempDir()
+			}
+			cache = filepath.Join(dir, "otel-semconv-checker")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+		g, version, err := semconv.Fetch(ctx, cfg.URL, cfg.Version, cache)
+		if err == nil {
+			slog.Info("using fetched semantic conventions", "version", version, "cache", cache)
+			return g, version, nil
+		}
+		slog.Warn("failed to fetch semantic conventions, using the compiled in version", "error", err)
+	}
+	g, err := semconv.ParseGroups()
+	if err != nil {
+		return nil, "", err
+	}
+	slog.Info("using semantic conventions", "version", semconv.Version)
+	return g, semconv.Version, nil
 }
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+// umask does nothing where there is no umask.
+func umask(mask int) func() {
+	return func() {}
+}
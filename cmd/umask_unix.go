@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// umask sets the process umask, until the returned func restores it.
+func umask(mask int) func() {
+	old := syscall.Umask(mask)
+	return func() { syscall.Umask(old) }
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// validate checks each of the trace payload files against the config without
+// starting a server, logging which matches applied and the attributes they
+// enforced. Files ending in .json are OTLP JSON, anything else is protobuf.
+// It returns the exit code, 1 if any file failed.
+func validate(cfg servers.Config, files []string) int {
+	setupLogging(cfg, os.Stdout, slog.LevelDebug)
+	cfg.OneShot = false
+	cfg.Signals.Trace.OneShot = nil
+	// Payloads are checked as if the server was long running, a warmup would
+	// make every violation advisory.
+	cfg.Warmup = 0
+
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: validate FILE...")
+		return 2
+	}
+
+	g, version, err := loadGroups(cfg)
+	if err != nil {
+		slog.Error("failed to parse groups", "error", err)
+		return 2
+	}
+	if cfg.Schema.URL == "" {
+		cfg.Schema.URL = version
+	}
+	srv, err := servers.NewTraceService(cfg, g, servers.WithGroupsVersion(version))
+	if err != nil {
+		slog.Error("invalid config", "error", err)
+		return 2
+	}
+
+	code := 0
+	for _, file := range files {
+		req, err := readTraces(file)
+		if err != nil {
+			slog.Error("failed to read payload", "file", file, "error", err)
+			return 2
+		}
+		if _, err := srv.Export(context.Background(), req); err != nil {
+			fmt.Printf("FAIL %s: %v\n", file, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("PASS %s\n", file)
+	}
+	return code
+}
+
+func readTraces(file string) (*pbCollectorTrace.ExportTraceServiceRequest, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	req := &pbCollectorTrace.ExportTraceServiceRequest{}
+	if filepath.Ext(file) == ".json" {
+		return req, protojson.Unmarshal(b, req)
+	}
+	return req, proto.Unmarshal(b, req)
+}
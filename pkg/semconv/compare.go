@@ -1,12 +1,39 @@
 package semconv
 
-import pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+import (
+	"slices"
+	"sync"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+var attrsPool = sync.Pool{
+	New: func() any { return map[string]bool{} },
+}
 
 func Compare(attrSlice []string, attributes []*pbCommon.KeyValue) (missing []string, extra []string) {
-	attrs := map[string]bool{}
+	return CompareAppend(nil, nil, attrSlice, attributes)
+}
+
+// CompareAppend is Compare, but appends to the provided missing and extra
+// slices so callers can reuse them. When every attribute matches nothing is
+// allocated. The appended attributes are sorted.
+func CompareAppend(missing, extra, attrSlice []string, attributes []*pbCommon.KeyValue) ([]string, []string) {
+	attrs := attrsPool.Get().(map[string]bool)
+	defer func() {
+		clear(attrs)
+		attrsPool.Put(attrs)
+	}()
+
 	for _, a := range attributes {
+		// An attribute without a value carries no information, so it does not
+		// satisfy a requirement.
+		if a.GetValue().GetValue() == nil {
+			continue
+		}
 		attrs[a.Key] = false
 	}
+	m, e := len(missing), len(extra)
 	for _, a := range attrSlice {
 		if _, ok := attrs[a]; !ok {
 			missing = append(missing, a)
@@ -19,9 +46,31 @@ func Compare(attrSlice []string, attributes []*pbCommon.KeyValue) (missing []str
 			extra = append(extra, k)
 		}
 	}
+	slices.Sort(missing[m:])
+	slices.Sort(extra[e:])
 	return missing, extra
 }
 
+// CompareAny compares the attributes against each of the attribute sets and
+// returns the result of the closest match, the set with the fewest missing,
+// along with its index. The index is -1 if there are no sets.
+func CompareAny(attrSets [][]string, attributes []*pbCommon.KeyValue) (missing []string, extra []string, best int) {
+	if len(attrSets) == 0 {
+		missing, extra = Compare(nil, attributes)
+		return missing, extra, -1
+	}
+	for i, attrSlice := range attrSets {
+		m, e := Compare(attrSlice, attributes)
+		if i == 0 || len(m) < len(missing) {
+			missing, extra, best = m, e, i
+		}
+		if len(missing) == 0 {
+			break
+		}
+	}
+	return missing, extra, best
+}
+
 func GetAttributes(groups ...Group) []string {
 	a := []string{}
 	for _, group := range groups {
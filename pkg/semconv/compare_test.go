@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
 )
 
 // NOTE ALL THESE ARE DEPENDANT ON THE SEMCONV.  THEY MAY CHANGE WITH THE SEMCONV.
@@ -79,3 +80,110 @@ func TestGetAttributes(t *testing.T) {
 		})
 	}
 }
+
+func stringKV(key, value string) *pbCommon.KeyValue {
+	return &pbCommon.KeyValue{
+		Key: key,
+		Value: &pbCommon.AnyValue{
+			Value: &pbCommon.AnyValue_StringValue{
+				StringValue: value,
+			},
+		},
+	}
+}
+
+func TestCompareNullValue(t *testing.T) {
+	attributes := []*pbCommon.KeyValue{
+		stringKV("server.address", "localhost"),
+		{Key: "server.port", Value: &pbCommon.AnyValue{}},
+		{Key: "url.path"},
+		{Key: "url.query"},
+	}
+	missing, extra := Compare([]string{"server.address", "server.port", "url.path"}, attributes)
+	assert.ElementsMatch(t, []string{"server.port", "url.path"}, missing)
+	assert.Empty(t, extra)
+}
+
+func TestCompareAny(t *testing.T) {
+	attributes := []*pbCommon.KeyValue{
+		stringKV("server.address", "localhost"),
+		stringKV("url.path", "/"),
+	}
+	tests := []struct {
+		name        string
+		attrSets    [][]string
+		wantMissing []string
+		wantExtra   []string
+		wantBest    int
+	}{
+		{
+			name:      "No sets",
+			wantExtra: []string{"server.address", "url.path"},
+			wantBest:  -1,
+		},
+		{
+			name: "One set satisfied",
+			attrSets: [][]string{
+				{"server.address", "server.port"},
+				{"url.path"},
+			},
+			wantExtra: []string{"server.address"},
+			wantBest:  1,
+		},
+		{
+			name: "Closest set",
+			attrSets: [][]string{
+				{"client.address", "client.port", "url.path"},
+				{"server.address", "server.port", "url.path"},
+			},
+			wantMissing: []string{"server.port"},
+			wantBest:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, extra, best := CompareAny(tt.attrSets, attributes)
+			assert.ElementsMatch(t, tt.wantMissing, missing)
+			assert.ElementsMatch(t, tt.wantExtra, extra)
+			assert.Equal(t, tt.wantBest, best)
+		})
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	attrSlice := []string{"http.request.method", "http.response.status_code", "http.route", "server.address", "server.port", "url.scheme"}
+	valid := []*pbCommon.KeyValue{}
+	for _, a := range attrSlice {
+		valid = append(valid, stringKV(a, "value"))
+	}
+	invalid := append([]*pbCommon.KeyValue{stringKV("http.method", "GET")}, valid[1:]...)
+
+	b.Run("Valid", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Compare(attrSlice, valid)
+		}
+	})
+	b.Run("Invalid", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Compare(attrSlice, invalid)
+		}
+	})
+	b.Run("InvalidAppend", func(b *testing.B) {
+		b.ReportAllocs()
+		var missing, extra []string
+		for i := 0; i < b.N; i++ {
+			missing, extra = CompareAppend(missing[:0], extra[:0], attrSlice, invalid)
+		}
+	})
+}
+
+func TestCompareSorted(t *testing.T) {
+	attrs := []*pbCommon.KeyValue{stringKV("d", "x"), stringKV("b", "x"), stringKV("c", "x"), stringKV("a", "x")}
+	for i := 0; i < 10; i++ {
+		missing, extra := Compare([]string{"z", "y", "c"}, attrs)
+		assert.Equal(t, []string{"y", "z"}, missing)
+		assert.Equal(t, []string{"a", "b", "d"}, extra)
+	}
+}
@@ -0,0 +1,149 @@
+package semconv
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	latestReleaseURL = "https://api.github.com/repos/open-telemetry/semantic-conventions/releases/latest"
+	archiveURL       = "https://github.com/open-telemetry/semantic-conventions/archive/refs/tags/%s.tar.gz"
+	schemaURL        = "https://opentelemetry.io/schemas/%s"
+)
+
+var versionRegexp = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+// Fetch downloads a semantic conventions release archive and parses the groups
+// of its model. If url is empty the latest upstream release is used, otherwise
+// url is an archive of the semantic-conventions repository and version is the
+// semconv version it contains, or is taken from the url's file name if empty.
+//
+// Extracted models are kept in cacheDir, one directory per version, and are
+// used instead of downloading the archive again.
+//
+// The returned version is the schema url of the groups.
+func Fetch(ctx context.Context, url, version, cacheDir string) (map[string]Group, string, error) {
+	if url == "" {
+		tag, err := latestTag(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		url = fmt.Sprintf(archiveURL, tag)
+		version = tag
+	}
+	if version == "" {
+		version = versionRegexp.FindString(path.Base(url))
+	}
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		return nil, "", fmt.Errorf("could not find a version for %s", url)
+	}
+
+	dir := filepath.Join(cacheDir, version)
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := download(ctx, url, dir); err != nil {
+			os.RemoveAll(dir)
+			return nil, "", err
+		}
+	}
+
+	groups, err := ParseGroupsFS(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, "", err
+	}
+	return groups, fmt.Sprintf(schemaURL, version), nil
+}
+
+func get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func latestTag(ctx context.Context) (string, error) {
+	resp, err := get(ctx, latestReleaseURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	release := struct {
+		TagName string `json:"tag_name"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("decoding latest release: %w", err)
+	}
+	return release.TagName, nil
+}
+
+// download extracts the yaml files in the model directory of the archive at
+// url into dir.
+func download(ctx context.Context, url, dir string) error {
+	resp, err := get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", url, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", url, err)
+		}
+		_, name, ok := strings.Cut(hdr.Name, "/model/")
+		if !ok || hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if !strings.HasPrefix(file, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
+		}
+		if err := writeFile(file, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
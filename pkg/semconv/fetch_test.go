@@ -0,0 +1,67 @@
+package semconv
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModel = `groups:
+  - id: acme
+    prefix: acme
+    type: attribute_group
+    attributes:
+      - id: team
+        type: string
+`
+
+func testArchive(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestFetch(t *testing.T) {
+	archive := testArchive(t, map[string]string{
+		"semantic-conventions-1.99.0/model/acme.yaml": testModel,
+		"semantic-conventions-1.99.0/docs/other.yaml": "not: [a model",
+		"semantic-conventions-1.99.0/model/README.md": "# Model",
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	url := srv.URL + "/v1.99.0.tar.gz"
+	cache := t.TempDir()
+
+	groups, version, err := Fetch(context.Background(), url, "", cache)
+	require.NoError(t, err)
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.99.0", version)
+	require.Contains(t, groups, "acme")
+	assert.Equal(t, "acme.team", groups["acme"].Attributes[0].CanonicalId)
+
+	// The second fetch is served from the cache.
+	srv.Close()
+	groups, _, err = Fetch(context.Background(), url, "", cache)
+	require.NoError(t, err)
+	assert.Contains(t, groups, "acme")
+}
@@ -1,10 +1,17 @@
 package semconv
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Group struct {
 	Id         string
 	Type       string
 	Extends    string
 	Attributes []Attribute
+	// SpanKind is the kind of span a span group applies to, any kind if empty.
+	SpanKind string `yaml:"span_kind"`
 
 	Prefix string
 }
@@ -17,3 +24,100 @@ type Attribute struct {
 	// This is space to hold the prefix.name after parsing.
 	CanonicalId string
 }
+
+// AddGroups adds custom groups to groups. A custom group can extend another
+// custom group or an existing one, its attributes are resolved through the
+// whole chain. Custom attributes only need a CanonicalId.
+func AddGroups(groups map[string]Group, custom ...Group) error {
+	byId := map[string]Group{}
+	for _, c := range custom {
+		if _, ok := groups[c.Id]; ok {
+			return fmt.Errorf("duplicate group id %s", c.Id)
+		}
+		if _, ok := byId[c.Id]; ok {
+			return fmt.Errorf("duplicate group id %s", c.Id)
+		}
+		byId[c.Id] = c
+	}
+
+	var resolve func(id string, chain []string) ([]Attribute, error)
+	resolve = func(id string, chain []string) ([]Attribute, error) {
+		c, ok := byId[id]
+		if !ok {
+			g, ok := groups[id]
+			if !ok {
+				return nil, fmt.Errorf("group %s extends unknown group %s", chain[len(chain)-1], id)
+			}
+			return g.Attributes, nil
+		}
+		for _, prev := range chain {
+			if prev == id {
+				return nil, fmt.Errorf("group %s extends itself: %s", id, strings.Join(append(chain, id), " -> "))
+			}
+		}
+		attrs := append([]Attribute{}, c.Attributes...)
+		if c.Extends != "" {
+			parent, err := resolve(c.Extends, append(chain, id))
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, parent...)
+		}
+		return attrs, nil
+	}
+
+	resolved := map[string]Group{}
+	for _, c := range custom {
+		attrs, err := resolve(c.Id, nil)
+		if err != nil {
+			return err
+		}
+		c.Attributes = attrs
+		c.Extends = ""
+		resolved[c.Id] = c
+	}
+	for id, g := range resolved {
+		groups[id] = g
+	}
+	return nil
+}
+
+// FilterDomains removes the groups outside the domains, when there are any,
+// and those in exclude. A group's domain is its id without a leading type,
+// such as "attributes." or "metric.", so "http" covers "attributes.http.common"
+// and "metric.http.server.request.duration".
+func FilterDomains(groups map[string]Group, domains, exclude []string) {
+	for id, g := range groups {
+		if (len(domains) > 0 && !inDomains(g, domains)) || inDomains(g, exclude) {
+			delete(groups, id)
+		}
+	}
+}
+
+func inDomains(g Group, domains []string) bool {
+	id := g.Id
+	for _, prefix := range []string{"attributes.", "metric.", "trace.", "resource.", "registry."} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	for _, d := range domains {
+		if id == d || strings.HasPrefix(id, d+".") || strings.HasPrefix(id, d+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributesOfType returns the attributes of every group of type typ, such as
+// "resource" or "span".
+func AttributesOfType(groups map[string]Group, typ string) map[string]bool {
+	attrs := map[string]bool{}
+	for _, g := range groups {
+		if g.Type != typ {
+			continue
+		}
+		for _, a := range g.Attributes {
+			attrs[a.CanonicalId] = true
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,89 @@
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func attributes(ids ...string) []Attribute {
+	attrs := []Attribute{}
+	for _, id := range ids {
+		attrs = append(attrs, Attribute{CanonicalId: id})
+	}
+	return attrs
+}
+
+func TestAddGroups(t *testing.T) {
+	groups, err := ParseGroups()
+	require.NoError(t, err)
+
+	err = AddGroups(groups,
+		Group{Id: "acme.http", Extends: "acme.base", Attributes: attributes("acme.route")},
+		Group{Id: "acme.base", Extends: "os", Attributes: attributes("acme.team")},
+	)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"acme.route",
+		"acme.team",
+		"os.type",
+		"os.description",
+		"os.name",
+		"os.version",
+		"os.build_id",
+	}, GetAttributes(groups["acme.http"]))
+}
+
+func TestAddGroupsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		custom []Group
+	}{
+		{
+			name:   "Duplicate",
+			custom: []Group{{Id: "os"}},
+		},
+		{
+			name:   "Unknown parent",
+			custom: []Group{{Id: "acme.base", Extends: "acme.missing"}},
+		},
+		{
+			name: "Cycle",
+			custom: []Group{
+				{Id: "acme.a", Extends: "acme.b"},
+				{Id: "acme.b", Extends: "acme.c"},
+				{Id: "acme.c", Extends: "acme.a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := ParseGroups()
+			require.NoError(t, err)
+			assert.Error(t, AddGroups(groups, tt.custom...))
+		})
+	}
+}
+
+func TestFilterDomains(t *testing.T) {
+	groups := map[string]Group{}
+	for _, id := range []string{"http", "attributes.http.common", "metric.http.server.request.duration", "db.redis", "faas_span.http", "host", "httpx"} {
+		groups[id] = Group{Id: id}
+	}
+	FilterDomains(groups, []string{"http", "db", "faas"}, []string{"db.redis"})
+	ids := []string{}
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	assert.ElementsMatch(t, []string{"http", "attributes.http.common", "metric.http.server.request.duration", "faas_span.http"}, ids)
+}
+
+func TestAttributesOfType(t *testing.T) {
+	groups, err := ParseGroups()
+	require.NoError(t, err)
+	resource := AttributesOfType(groups, "resource")
+	assert.True(t, resource["service.name"])
+	assert.False(t, resource["http.route"])
+	assert.True(t, AttributesOfType(groups, "span")["http.route"])
+}
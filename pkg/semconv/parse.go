@@ -23,13 +23,21 @@ func fileError(path string, err error) error {
 }
 
 func ParseGroups() (map[string]Group, error) {
+	return ParseGroupsFS(files, "src")
+}
+
+// ParseGroupsFS parses the groups of every yaml file under root in fsys.
+func ParseGroupsFS(fsys fs.FS, root string) (map[string]Group, error) {
 	groups := make(map[string]Group)
-	err := fs.WalkDir(files, "src", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if !strings.HasSuffix(d.Name(), ".yaml") {
 			return nil
 		}
 		var raw File
-		b, err := files.ReadFile(path)
+		b, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return fileError(path, err)
 		}
@@ -0,0 +1,72 @@
+package servers
+
+import (
+	"log/slog"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// Alias lets a legacy attribute, From, satisfy the semantic convention
+// attribute To. Warn logs each time the legacy form is used.
+type Alias struct {
+	From string
+	To   string
+	Warn bool
+}
+
+type aliases map[string]Alias
+
+func newAliases(cfg []Alias) aliases {
+	a := aliases{}
+	for _, alias := range cfg {
+		a[alias.From] = alias
+	}
+	return a
+}
+
+// apply returns the attributes with legacy keys renamed to the ones they
+// alias, unless that is already present. attrs is only copied if an alias is
+// used.
+func (a aliases) apply(log *slog.Logger, attrs []*pbCommon.KeyValue) []*pbCommon.KeyValue {
+	if len(a) == 0 {
+		return attrs
+	}
+	var renamed []*pbCommon.KeyValue
+	for i, kv := range attrs {
+		alias, ok := a[kv.Key]
+		if !ok || hasKey(attrs, alias.To) {
+			continue
+		}
+		if alias.Warn {
+			log.Warn("legacy attribute", slog.String("attribute", alias.From), slog.String("use", alias.To))
+		}
+		if renamed == nil {
+			renamed = append([]*pbCommon.KeyValue{}, attrs...)
+		}
+		renamed[i] = &pbCommon.KeyValue{Key: alias.To, Value: kv.Value}
+	}
+	if renamed == nil {
+		return attrs
+	}
+	return renamed
+}
+
+// presentKeys returns the keys that are in attrs.
+func presentKeys(attrs []*pbCommon.KeyValue, keys []string) []string {
+	var present []string
+	for _, key := range keys {
+		if hasKey(attrs, key) {
+			present = append(present, key)
+		}
+	}
+	return present
+}
+
+func hasKey(attrs []*pbCommon.KeyValue, key string) bool {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return true
+		}
+	}
+	return false
+}
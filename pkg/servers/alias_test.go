@@ -0,0 +1,26 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestAliases(t *testing.T) {
+	a := newAliases([]Alias{{From: "http_method", To: "http.request.method"}})
+	required := []string{"http.request.method"}
+
+	attrs := []*pbCommon.KeyValue{stringKV("http_method", "GET")}
+	missing, extra := semconv.Compare(required, a.apply(slog.Default(), attrs))
+	assert.Empty(t, missing)
+	assert.Empty(t, extra)
+	assert.Equal(t, "http_method", attrs[0].Key)
+
+	attrs = []*pbCommon.KeyValue{stringKV("http_method", "GET"), stringKV("http.request.method", "GET")}
+	missing, extra = semconv.Compare(required, a.apply(slog.Default(), attrs))
+	assert.Empty(t, missing)
+	assert.Equal(t, []string{"http_method"}, extra)
+}
@@ -0,0 +1,102 @@
+package servers
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultAuditQueueSize = 1024
+
+// AuditLog is a Sink that appends violations to a file as JSON lines. Writes
+// are queued and written in the background, when the queue is full violations
+// are dropped and counted, as are writes after Close.
+type AuditLog struct {
+	f       *os.File
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan Violation
+	dropped atomic.Uint64
+	done    chan error
+}
+
+func NewAuditLog(cfg AuditConfig) (*AuditLog, error) {
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultAuditQueueSize
+	}
+	a := &AuditLog{
+		f:     f,
+		queue: make(chan Violation, size),
+		done:  make(chan error, 1),
+	}
+	go a.run()
+	return a, nil
+}
+
+func (a *AuditLog) Write(v Violation) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		a.dropped.Add(1)
+		return
+	}
+	select {
+	case a.queue <- v:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Len is the number of violations waiting to be written.
+func (a *AuditLog) Len() int {
+	return len(a.queue)
+}
+
+// Dropped is the number of violations that did not fit in the queue.
+func (a *AuditLog) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Close writes the queued violations and closes the file.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	close(a.queue)
+	a.mu.Unlock()
+	err := <-a.done
+	if dropped := a.Dropped(); dropped > 0 {
+		slog.Warn("dropped audit log violations", slog.Uint64("dropped", dropped))
+	}
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (a *AuditLog) run() {
+	w := bufio.NewWriter(a.f)
+	enc := json.NewEncoder(w)
+	var err error
+	for v := range a.queue {
+		if err == nil {
+			err = enc.Encode(v)
+		}
+		if err == nil && len(a.queue) == 0 {
+			err = w.Flush()
+		}
+		if err != nil {
+			slog.Error("failed to write audit log", slog.String("error", err.Error()))
+			err = nil
+			w.Reset(a.f)
+		}
+	}
+	a.done <- w.Flush()
+}
@@ -0,0 +1,48 @@
+package servers
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	want := []Violation{
+		{Service: "svc", Signal: "trace", Name: "GET /", Attribute: "http.route", Kind: ViolationMissing},
+		{Service: "svc", Signal: "trace", Name: "GET /", Attribute: "error.type", Kind: ViolationRule},
+	}
+
+	for _, v := range want {
+		a, err := NewAuditLog(AuditConfig{Path: path})
+		require.NoError(t, err)
+		a.Write(v)
+		require.NoError(t, a.Close())
+		assert.Zero(t, a.Dropped())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	got := []Violation{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		v := Violation{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &v))
+		got = append(got, v)
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestAuditLogWriteAfterClose(t *testing.T) {
+	a, err := NewAuditLog(AuditConfig{Path: filepath.Join(t.TempDir(), "audit.jsonl")})
+	require.NoError(t, err)
+	require.NoError(t, a.Close())
+	a.Write(Violation{Attribute: "http.route", Kind: ViolationMissing})
+	assert.Equal(t, uint64(1), a.Dropped())
+}
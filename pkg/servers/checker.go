@@ -0,0 +1,64 @@
+package servers
+
+import (
+	"log/slog"
+	"time"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Checker is the checking core, for receivers of telemetry that is not OTLP.
+// A receiver converts its payloads to Items and submits them, the violations
+// are returned and sent to the sinks like those of an export.
+type Checker interface {
+	Check(log *slog.Logger, items ...Item) []Violation
+}
+
+// Item is one named piece of telemetry and its attributes.
+type Item struct {
+	Service string
+	// Scope is the name of the instrumentation scope, matched by Match.Scope.
+	Scope      string
+	Name       string
+	Kind       pbTrace.Span_SpanKind
+	Attributes []*pbCommon.KeyValue
+	// Resource is the attributes of what produced the item, for resource rules.
+	Resource []*pbCommon.KeyValue
+}
+
+var _ Checker = (*TraceServer)(nil)
+
+// Check checks the items against the trace matches. Unlike Export, it does not
+// check resources or scopes, nor count towards one shot mode.
+func (s *TraceServer) Check(log *slog.Logger, items ...Item) []Violation {
+	now := time.Now()
+	violations := []Violation{}
+	for _, item := range items {
+		log := log.With(slog.String("name", item.Name))
+		span := &pbTrace.Span{Name: item.Name, Kind: item.Kind, Attributes: item.Attributes}
+		for _, i := range s.lookup(log, item.Name) {
+			match := s.matches[i]
+			if match.scope != nil && !match.scope.MatchString(item.Scope) {
+				continue
+			}
+			s.hits[i].Add(1)
+			vs, _ := s.checkMatch(log, match, span, item.Resource)
+			for _, v := range vs {
+				v.Time = now
+				v.Service = item.Service
+				v.Signal = "trace"
+				v.Name = item.Name
+				v.Advisory = match.advisory
+				v.Match = match.pattern
+				violations = append(violations, v)
+			}
+		}
+	}
+	for _, sink := range s.sinks {
+		for _, v := range violations {
+			sink.Write(v)
+		}
+	}
+	return violations
+}
@@ -1,20 +1,396 @@
 package servers
 
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+)
+
 type Config struct {
-	ServerAddress   string `mapstructure:"server_address"`
-	Resource        Match
+	// ServerAddress is a tcp host:port, or unix:///path for a socket created
+	// with SocketMode permissions, 0660 by default.
+	ServerAddress   string        `mapstructure:"server_address"`
+	SocketMode      string        `mapstructure:"socket_mode"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// AdminAddress is where the /livez, /readyz, /metrics and /coverage
+	// endpoints are served, they are off if it is empty.
+	AdminAddress string `mapstructure:"admin_address"`
+	// AdminToken enables the /mode endpoint, to switch between enforcing and
+	// observing and set the log level, for requests with it as a bearer token.
+	AdminToken string `mapstructure:"admin_token"`
+	GRPC       GRPCConfig
+	// Exclude skips resources with any of these attribute values entirely,
+	// their spans are neither checked nor reported as unmatched.
+	Exclude  []Expected
+	Resource Match
+	// ScopeAttributes checks the attributes of instrumentation scopes, when it
+	// has groups. Like the resource, problems are logged but not counted.
+	ScopeAttributes Match `mapstructure:"scope_attributes"`
 	Trace           []Match
 	Metric          []Match
 	Log             []Match
-	ReportUnmatched bool `mapstructure:"report_unmatched"`
-	OneShot         bool `mapstructure:"one_shot"`
+	Groups          []Group
+	Aliases         []Alias
+	Limits          Limits
+	Threshold       Threshold
+	// Misplaced checks for resource attributes on spans and span attributes on
+	// resources.
+	Misplaced Policy
+	// StatusMessage checks that error spans have a status message, and other
+	// spans do not.
+	StatusMessage   Policy `mapstructure:"status_message"`
+	ReportUnmatched bool   `mapstructure:"report_unmatched"`
+	OneShot         bool   `mapstructure:"one_shot"`
+	// Warmup is how long after the server starts serving violations are only
+	// reported, as if every match was advisory.
+	Warmup    time.Duration
+	Signals   Signals
+	Semconv   SemconvConfig
+	Schema    SchemaConfig
+	Audit     AuditConfig
+	Report    ReportConfig
+	LogExport LogExportConfig `mapstructure:"log_export"`
+	LogLevel  string          `mapstructure:"log_level"`
+	LogFormat LogFormat       `mapstructure:"log_format"`
+	LogKeys   []LogKey        `mapstructure:"log_keys"`
+	LogValues bool            `mapstructure:"log_values"`
+	Redact    Redact
+}
+
+// GRPCConfig limits what the server accepts. MaxRecvMsgSize defaults to 4MiB
+// and streams are unlimited by default. A RetryDelay adds a RetryInfo, with up
+// to RetryJitter added at random, to rejected exports so clients back off.
+// MaxConnections limits the open connections, the rest wait to be accepted,
+// and the keepalive settings are grpc's when zero.
+type GRPCConfig struct {
+	MaxRecvMsgSize        int           `mapstructure:"max_recv_msg_size"`
+	MaxConcurrentStreams  uint32        `mapstructure:"max_concurrent_streams"`
+	RetryDelay            time.Duration `mapstructure:"retry_delay"`
+	RetryJitter           time.Duration `mapstructure:"retry_jitter"`
+	MaxConnections        int           `mapstructure:"max_connections"`
+	MaxConnectionIdle     time.Duration `mapstructure:"max_connection_idle"`
+	MaxConnectionAge      time.Duration `mapstructure:"max_connection_age"`
+	MaxConnectionAgeGrace time.Duration `mapstructure:"max_connection_age_grace"`
+	KeepaliveTime         time.Duration `mapstructure:"keepalive_time"`
+	KeepaliveTimeout      time.Duration `mapstructure:"keepalive_timeout"`
+	// MinPingInterval is how often clients may ping, more often closes the
+	// connection.
+	MinPingInterval     time.Duration `mapstructure:"min_ping_interval"`
+	PermitWithoutStream bool          `mapstructure:"permit_without_stream"`
+	// Summary sets the partial success message of accepted exports to a
+	// summary of the violations, such as violations=2;advisory=1;spans=10, for
+	// clients to log when violations are only reported.
+	Summary bool
+}
+
+// SemconvConfig fetches the semantic conventions at startup instead of using
+// the compiled in version. URL is a release archive of the semantic-conventions
+// repository, the latest release if empty, and Version is the version it
+// contains. Downloaded models are kept in Cache. Only the groups in Domains,
+// all if empty, and not in ExcludeDomains are loaded.
+type SemconvConfig struct {
+	Fetch          bool
+	URL            string
+	Version        string
+	Cache          string
+	Domains        []string
+	ExcludeDomains []string `mapstructure:"exclude_domains"`
+}
+
+type SchemaConfig struct {
+	// URL is the schema url resources and scopes are expected to have. It
+	// defaults to the version of the semantic conventions in use.
+	URL string
+	// Versions are the accepted schema versions, each a version or space
+	// separated constraints such as ">=1.24.0 <1.28.0". A url passes if it
+	// matches any of them, or URL when there are none.
+	Versions []string
+	// Mismatch is what happens to a resource or scope whose version is not
+	// accepted. ScopeMismatch, if set, is used for scopes instead.
+	Mismatch      Policy
+	ScopeMismatch Policy `mapstructure:"scope_mismatch"`
+	// ReportMissing reports resources without a schema url separately from
+	// ones with an incorrect version, at MissingLevel (warn by default).
+	ReportMissing bool   `mapstructure:"report_missing"`
+	MissingLevel  string `mapstructure:"missing_level"`
+	// CheckGroups warns when a resource's schema url is a different minor
+	// version than the semantic conventions its attributes are checked with.
+	CheckGroups bool `mapstructure:"check_groups"`
+}
+
+// Validate checks that every group the config refers to exists, that
+// levels, policies and formats have known values, and that regular
+// expressions compile.
+func (c Config) Validate(g map[string]semconv.Group) error {
+	var errs []error
+	policy := func(key string, p Policy) {
+		if !p.valid() {
+			errs = append(errs, fmt.Errorf("%s: unknown policy %q, expected ignore, warn or fail", key, p))
+		}
+	}
+	level := func(key, s string) {
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(s)); s != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s: unknown level %q", key, s))
+		}
+	}
+	pattern := func(key, p string) {
+		if _, err := regexp.Compile(p); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	check := func(section string, m Match) {
+		for _, name := range append(append([]string{}, m.Groups...), m.AnyOf...) {
+			if _, ok := g[name]; name != "" && !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown group %q", section, name))
+			}
+		}
+		policy(section+": extra", m.Extra)
+		for _, r := range m.Rules {
+			pattern(section+": rules", r.Value)
+		}
+		pattern(section+": scope", m.Scope)
+		for _, v := range m.Values {
+			pattern(section+": values", v.Pattern)
+		}
+	}
+	level("log_level", c.LogLevel)
+	level("schema.missing_level", c.Schema.MissingLevel)
+	policy("misplaced", c.Misplaced)
+	policy("status_message", c.StatusMessage)
+	policy("schema.mismatch", c.Schema.Mismatch)
+	policy("schema.scope_mismatch", c.Schema.ScopeMismatch)
+	if _, err := newVersionCheck("", c.Schema.Versions); err != nil {
+		errs = append(errs, fmt.Errorf("schema.versions: %w", err))
+	}
+	switch c.Report.Format {
+	case ReportJSON, ReportJUnit, "":
+	default:
+		errs = append(errs, fmt.Errorf("report.format: unknown format %q, expected json or junit", c.Report.Format))
+	}
+	switch c.LogFormat {
+	case LogText, LogJSON, LogDiff, "":
+	default:
+		errs = append(errs, fmt.Errorf("log_format: unknown format %q, expected text, json or diff", c.LogFormat))
+	}
+	for _, v := range c.Redact.Values {
+		pattern("redact.values", v)
+	}
+	for _, e := range c.Exclude {
+		pattern("exclude", e.Pattern)
+	}
+	check("resource", c.Resource)
+	check("scope_attributes", c.ScopeAttributes)
+	for signal, matches := range map[string][]Match{"trace": c.Trace, "metric": c.Metric, "log": c.Log} {
+		for _, m := range matches {
+			section := fmt.Sprintf("%s match %q", signal, m.Match)
+			pattern(section, m.Match)
+			check(section, m)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Level is the configured log level, info by default or when it is invalid,
+// which Validate reports.
+func (c Config) Level() slog.Level {
+	return parseLevel(c.LogLevel, slog.LevelInfo)
+}
+
+func parseLevel(s string, def slog.Level) slog.Level {
+	var level slog.Level
+	if s == "" || level.UnmarshalText([]byte(s)) != nil {
+		return def
+	}
+	return level
+}
+
+type Signals struct {
+	Trace  SignalConfig
+	Metric SignalConfig
+	Log    SignalConfig
+}
+
+// SignalConfig holds the settings of a single signal's server. Unset fields
+// fall back to the top level value of the same name.
+type SignalConfig struct {
+	ReportUnmatched *bool `mapstructure:"report_unmatched"`
+	OneShot         *bool `mapstructure:"one_shot"`
+	// SkipResource turns off checking the resource schema url and attributes.
+	SkipResource bool `mapstructure:"skip_resource"`
+}
+
+func boolOr(b *bool, def bool) bool {
+	if b != nil {
+		return *b
+	}
+	return def
+}
+
+// AuditConfig appends every violation to the JSON lines file at Path, if set.
+// QueueSize bounds how many violations can wait to be written.
+type AuditConfig struct {
+	Path      string
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// Threshold lets an export with violations succeed, as long as it has no more
+// than Count enforced violations or no more than Percent of its spans have
+// one. Both are off when zero.
+type Threshold struct {
+	Count   int
+	Percent float64
+}
+
+func (t Threshold) tolerates(violations, failed, total int) bool {
+	if t.Count > 0 && violations <= t.Count {
+		return true
+	}
+	return t.Percent > 0 && total > 0 && float64(failed)/float64(total)*100 <= t.Percent
+}
+
+// LogExportConfig sends every violation as an OTLP log record to the
+// collector at Endpoint, if set, every Interval (5s by default). QueueSize
+// bounds how many violations can wait to be sent.
+type LogExportConfig struct {
+	Endpoint  string
+	Insecure  bool
+	QueueSize int `mapstructure:"queue_size"`
+	Interval  time.Duration
+}
+
+// ReportConfig writes the violations to Path when the checker exits, for CI
+// runs in one shot mode. Format is json, the default, or junit.
+type ReportConfig struct {
+	Path   string
+	Format string
+}
+
+// LogFormat is text by default. Diff is the text format with the missing and
+// extra attributes of each span on one line, "+extra -missing".
+type LogFormat string
+
+const (
+	LogText LogFormat = "text"
+	LogJSON LogFormat = "json"
+	LogDiff LogFormat = "diff"
+)
+
+// LogKey renames the From key of the logs to To, for example name to
+// span_name. It applies to the top level keys, including time, level and msg.
+type LogKey struct {
+	From string
+	To   string
+}
+
+// Redact lists the attributes whose values are replaced with "***" when
+// log_values is set. Keys are exact attribute keys, Values are regular
+// expressions matched against the value.
+type Redact struct {
+	Keys   []string
+	Values []string
+}
+
+// Group is a custom group of attributes, that can be used in matches like the
+// semantic convention groups. Attributes are full attribute names.
+type Group struct {
+	Id         string
+	Extends    string
+	Attributes []string
+}
+
+// CustomGroups returns the configured groups to add with semconv.AddGroups.
+func (c Config) CustomGroups() []semconv.Group {
+	groups := []semconv.Group{}
+	for _, g := range c.Groups {
+		group := semconv.Group{Id: g.Id, Extends: g.Extends}
+		for _, a := range g.Attributes {
+			group.Attributes = append(group.Attributes, semconv.Attribute{Id: a, CanonicalId: a})
+		}
+		groups = append(groups, group)
+	}
+	return groups
 }
 
 type Match struct {
-	Match            string
+	Match string
+	// Scope, a regular expression, limits the match to spans from matching
+	// instrumentation scopes. With an empty Match it applies to every span in
+	// the scope.
+	Scope            string
 	Groups           []string
+	AnyOf            []string `mapstructure:"any_of"`
 	Ignore           []string
-	ReportAdditional bool `mapstructure:"report_additional"`
+	AllowedExtra     []string `mapstructure:"allowed_extra"`
+	ReportAdditional bool     `mapstructure:"report_additional"`
+	Extra            Policy
+	Rules            []Rule
+	Values           []Expected
+	// Advisory matches report their violations without them counting against
+	// the export or the one shot exit code.
+	Advisory bool
+	// MaxChecked is the most spans the match checks in an export, the rest
+	// are assumed to look like them. Zero checks every span.
+	MaxChecked int `mapstructure:"max_checked"`
+	// Priority orders the matches that apply to a span, higher first and then
+	// in config order. Each violation records the match that found it.
+	Priority int
+}
+
+// Expected is the value an attribute must have when it is present, or the
+// regular expression, Pattern, it must match. Whether it has to be present is
+// up to the groups.
+type Expected struct {
+	Key     string
+	Value   string
+	Pattern string
+}
+
+// Rule requires the Then attributes whenever the If attribute is present and,
+// when Value is set, its value matches the Value regular expression. With
+// Resource the If attribute is looked up on the resource instead, such as
+// cloud.provider.
+type Rule struct {
+	If       string
+	Value    string
+	Resource bool
+	Then     []string
+}
+
+// Policy is what to do with a kind of problem: ignore it, warn, logging it
+// without counting it as a failure, or fail, counting it against the export
+// and the one shot exit code. Unset policies default to:
+//
+//   - extra: warn. Extra resource and scope attributes are never counted.
+//   - misplaced and status_message: ignore, the check is off.
+//   - schema.mismatch: logged at info, not warn.
+//   - schema.scope_mismatch: the schema.mismatch policy.
+type Policy string
+
+const (
+	PolicyIgnore Policy = "ignore"
+	PolicyWarn   Policy = "warn"
+	PolicyFail   Policy = "fail"
+)
+
+func (p Policy) valid() bool {
+	switch p {
+	case "", PolicyIgnore, PolicyWarn, PolicyFail:
+		return true
+	}
+	return false
+}
+
+// or returns the policy, or def when it is unset.
+func (p Policy) or(def Policy) Policy {
+	if p == "" {
+		return def
+	}
+	return p
 }
 
 var DefaultConfig = `---
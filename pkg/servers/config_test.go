@@ -0,0 +1,57 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	g := map[string]semconv.Group{"host": {Id: "host"}}
+	assert.NoError(t, Config{Resource: Match{Groups: []string{"host", ""}}}.Validate(g))
+	err := Config{Trace: []Match{{Match: "^GET", AnyOf: []string{"nope"}}}}.Validate(g)
+	assert.ErrorContains(t, err, `trace match "^GET": unknown group "nope"`)
+
+	err = Config{
+		LogLevel:      "loud",
+		StatusMessage: "error",
+		Report:        ReportConfig{Format: "html"},
+		Trace:         []Match{{Match: "^GET", Extra: "drop"}},
+	}.Validate(g)
+	assert.ErrorContains(t, err, `log_level: unknown level "loud"`)
+	assert.ErrorContains(t, err, `status_message: unknown policy "error"`)
+	assert.ErrorContains(t, err, `report.format: unknown format "html"`)
+	assert.ErrorContains(t, err, `trace match "^GET": extra: unknown policy "drop"`)
+	assert.Equal(t, slog.LevelInfo, Config{LogLevel: "loud"}.Level())
+}
+
+func TestValidateRules(t *testing.T) {
+	err := Config{Trace: []Match{{Match: "^GET", Rules: []Rule{{If: "http.response.status_code", Value: "^(5", Then: []string{"error.type"}}}}}}.Validate(nil)
+	assert.ErrorContains(t, err, `trace match "^GET": rules: error parsing regexp`)
+}
+
+func TestValidateScope(t *testing.T) {
+	err := Config{Trace: []Match{{Match: "^GET", Scope: "otelhttp["}}}.Validate(nil)
+	assert.ErrorContains(t, err, `trace match "^GET": scope: error parsing regexp`)
+}
+
+func TestValidateValues(t *testing.T) {
+	err := Config{Trace: []Match{{Match: "^GET", Values: []Expected{{Key: "http.route", Pattern: "^/(users"}}}}}.Validate(nil)
+	assert.ErrorContains(t, err, `trace match "^GET": values: error parsing regexp`)
+}
+
+func TestValidateRedact(t *testing.T) {
+	err := Config{Redact: Redact{Values: []string{"Bearer (.*"}}}.Validate(nil)
+	assert.ErrorContains(t, err, `redact.values: error parsing regexp`)
+}
+
+func TestValidatePatterns(t *testing.T) {
+	err := Config{
+		Exclude: []Expected{{Key: "service.name", Pattern: "test-(.*"}},
+		Trace:   []Match{{Match: "GET (/"}},
+	}.Validate(nil)
+	assert.ErrorContains(t, err, `exclude: error parsing regexp`)
+	assert.ErrorContains(t, err, `trace match "GET (/": error parsing regexp`)
+}
@@ -0,0 +1,38 @@
+package servers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// Coverage is the number of spans a trace match has checked.
+type Coverage struct {
+	Match string `json:"match"`
+	Hits  uint64 `json:"hits"`
+}
+
+// Coverage returns the hits of every trace match, in config order.
+func (s *TraceServer) Coverage() []Coverage {
+	coverage := make([]Coverage, len(s.matches))
+	for i, m := range s.matches {
+		coverage[i] = Coverage{Match: m.pattern, Hits: s.hits[i].Load()}
+	}
+	return coverage
+}
+
+// LogUnused logs the trace matches that have never matched a span, they are
+// usually typos or for spans that were renamed.
+func (s *TraceServer) LogUnused() {
+	for _, c := range s.Coverage() {
+		if c.Hits == 0 {
+			slog.Warn("unused match", slog.String("type", "trace"), slog.String("match", c.Match))
+		}
+	}
+}
+
+// ServeCoverage serves the Coverage as JSON.
+func (s *TraceServer) ServeCoverage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Coverage())
+}
@@ -0,0 +1,138 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net"
+	"time"
+
+	"golang.org/x/net/netutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const defaultMaxRecvMsgSize = 4 << 20
+
+// ServerOptions returns the grpc.ServerOptions for the configured limits.
+func ServerOptions(cfg Config) []grpc.ServerOption {
+	maxRecvMsgSize := cfg.GRPC.MaxRecvMsgSize
+	if maxRecvMsgSize == 0 {
+		maxRecvMsgSize = defaultMaxRecvMsgSize
+	}
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxRecvMsgSize),
+		grpc.StatsHandler(rejectedLogger{}),
+	}
+	if cfg.GRPC.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.GRPC.MaxConcurrentStreams))
+	}
+	opts = append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.GRPC.MaxConnectionIdle,
+			MaxConnectionAge:      cfg.GRPC.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.GRPC.MaxConnectionAgeGrace,
+			Time:                  cfg.GRPC.KeepaliveTime,
+			Timeout:               cfg.GRPC.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPC.MinPingInterval,
+			PermitWithoutStream: cfg.GRPC.PermitWithoutStream,
+		}),
+	)
+	return opts
+}
+
+// LimitListener limits lis to the configured MaxConnections, if any.
+func LimitListener(lis net.Listener, cfg Config) net.Listener {
+	if cfg.GRPC.MaxConnections <= 0 {
+		return lis
+	}
+	return netutil.LimitListener(lis, cfg.GRPC.MaxConnections)
+}
+
+// maxDetails keeps the status details, which are sent in the trailers, under
+// the default grpc header size limits.
+const maxDetails = 50
+
+// withViolations adds a PreconditionFailure listing the enforced violations to
+// st. The type is the kind of violation, the subject the span name and the
+// description the attribute.
+func withViolations(st *status.Status, violations []Violation) *status.Status {
+	failure := &errdetails.PreconditionFailure{}
+	for _, v := range violations {
+		if v.Advisory {
+			continue
+		}
+		if len(failure.Violations) == maxDetails {
+			break
+		}
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        v.Kind,
+			Subject:     v.Name,
+			Description: v.Attribute,
+		})
+	}
+	withFailure, err := st.WithDetails(failure)
+	if err != nil {
+		return st
+	}
+	return withFailure
+}
+
+// withRetry adds a RetryInfo to st if cfg has a RetryDelay.
+func withRetry(st *status.Status, cfg GRPCConfig) *status.Status {
+	if cfg.RetryDelay <= 0 {
+		return st
+	}
+	delay := cfg.RetryDelay
+	if cfg.RetryJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(cfg.RetryJitter)))
+	}
+	withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if err != nil {
+		return st
+	}
+	return withInfo
+}
+
+type methodKey struct{}
+
+// rejectedLogger logs the requests grpc rejects before they reach a server,
+// such as messages over the size limit.
+type rejectedLogger struct{}
+
+func (rejectedLogger) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, methodKey{}, info.FullMethodName)
+}
+
+func (rejectedLogger) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok || status.Code(end.Error) != codes.ResourceExhausted {
+		return
+	}
+	method, _ := ctx.Value(methodKey{}).(string)
+	slog.Warn("rejected request",
+		slog.String("method", method),
+		slog.String("error", status.Convert(end.Error).Message()),
+	)
+}
+
+func (rejectedLogger) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (rejectedLogger) HandleConn(context.Context, stats.ConnStats) {}
+
+// summary is the partial success message of an accepted export, a ; separated
+// list of key=value counts.
+func summary(violations, enforced, spans, failedSpans int) string {
+	return fmt.Sprintf("violations=%d;advisory=%d;spans=%d;failed_spans=%d",
+		enforced, violations-enforced, spans, failedSpans)
+}
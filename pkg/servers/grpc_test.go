@@ -0,0 +1,37 @@
+package servers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithRetry(t *testing.T) {
+	st := status.New(codes.FailedPrecondition, "missing attributes")
+	assert.Empty(t, withRetry(st, GRPCConfig{}).Details())
+
+	details := withRetry(st, GRPCConfig{RetryDelay: time.Second, RetryJitter: time.Second}).Details()
+	require.Len(t, details, 1)
+	delay := details[0].(*errdetails.RetryInfo).RetryDelay.AsDuration()
+	assert.GreaterOrEqual(t, delay, time.Second)
+	assert.Less(t, delay, 2*time.Second)
+}
+
+func TestWithViolations(t *testing.T) {
+	st := withViolations(status.New(codes.FailedPrecondition, "missing attributes"), []Violation{
+		{Name: "GET /", Attribute: "http.route", Kind: ViolationMissing},
+		{Name: "GET /", Attribute: "url.path", Kind: ViolationMissing, Advisory: true},
+	})
+	details := st.Details()
+	require.Len(t, details, 1)
+	violations := details[0].(*errdetails.PreconditionFailure).Violations
+	require.Len(t, violations, 1)
+	assert.Equal(t, "missing", violations[0].Type)
+	assert.Equal(t, "GET /", violations[0].Subject)
+	assert.Equal(t, "http.route", violations[0].Description)
+}
@@ -0,0 +1,52 @@
+package servers
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	pbHealth "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Health reports liveness and readiness. The checker is live as soon as the
+// process is up and ready once the groups and config are loaded and the
+// servers are registered, until it starts shutting down.
+type Health struct {
+	ready atomic.Bool
+	grpc  *health.Server
+}
+
+func NewHealth() *Health {
+	h := &Health{grpc: health.NewServer()}
+	h.grpc.SetServingStatus("", pbHealth.HealthCheckResponse_NOT_SERVING)
+	return h
+}
+
+// Register adds the gRPC health service to s.
+func (h *Health) Register(s *grpc.Server) {
+	pbHealth.RegisterHealthServer(s, h.grpc)
+}
+
+// Handle adds the /livez and /readyz endpoints to mux.
+func (h *Health) Handle(mux *http.ServeMux) {
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !h.ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+}
+
+func (h *Health) SetReady(ready bool) {
+	h.ready.Store(ready)
+	status := pbHealth.HealthCheckResponse_NOT_SERVING
+	if ready {
+		status = pbHealth.HealthCheckResponse_SERVING
+	}
+	h.grpc.SetServingStatus("", status)
+}
@@ -0,0 +1,35 @@
+package servers
+
+import (
+	"context"
+	"log/slog"
+
+	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+)
+
+// PreExportHook runs before an export is checked. The logger it returns is
+// used for the export, returning false accepts the export without checking it.
+type PreExportHook func(ctx context.Context, log *slog.Logger, req *pbCollectorTrace.ExportTraceServiceRequest) (*slog.Logger, bool)
+
+// PostExportHook runs after an export has been checked, with what was found
+// and the error returned to the client.
+type PostExportHook func(ctx context.Context, result ExportResult)
+
+type ExportResult struct {
+	Violations []Violation
+	// Enforced is the number of violations that are not advisory.
+	Enforced int
+	Err      error
+}
+
+func WithPreExport(hook PreExportHook) Option {
+	return func(s *TraceServer) {
+		s.preExport = hook
+	}
+}
+
+func WithPostExport(hook PostExportHook) Option {
+	return func(s *TraceServer) {
+		s.postExport = hook
+	}
+}
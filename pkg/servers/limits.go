@@ -0,0 +1,63 @@
+package servers
+
+import (
+	"log/slog"
+	"unicode/utf8"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// Limits flags spans that OTEL attribute limits would truncate. AttributeCount
+// is the most attributes a span can have and ValueLength the longest string
+// value, zero turns either off.
+type Limits struct {
+	AttributeCount int `mapstructure:"attribute_count"`
+	ValueLength    int `mapstructure:"value_length"`
+}
+
+// limitAttributeCount is the violation attribute for too many attributes.
+const limitAttributeCount = "attribute_count"
+
+// checkLimits logs each limit that is exceeded, and returns the attributes
+// that are too long, or limitAttributeCount if there are too many.
+func checkLimits(log *slog.Logger, limits Limits, attributes []*pbCommon.KeyValue) []string {
+	exceeded := []string{}
+	if limits.AttributeCount > 0 && len(attributes) > limits.AttributeCount {
+		log.Info("limit exceeded",
+			slog.String("limit", limitAttributeCount),
+			slog.Int("count", len(attributes)),
+			slog.Int("max", limits.AttributeCount),
+		)
+		exceeded = append(exceeded, limitAttributeCount)
+	}
+	if limits.ValueLength > 0 {
+		for _, kv := range attributes {
+			if length := valueLength(kv.Value); length > limits.ValueLength {
+				log.Info("limit exceeded",
+					slog.String("limit", "value_length"),
+					slog.String("attribute", kv.Key),
+					slog.Int("length", length),
+					slog.Int("max", limits.ValueLength),
+				)
+				exceeded = append(exceeded, kv.Key)
+			}
+		}
+	}
+	return exceeded
+}
+
+// valueLength is the length, in characters, of a string value, or of the longest string in an
+// array.
+func valueLength(v *pbCommon.AnyValue) int {
+	switch v := v.GetValue().(type) {
+	case *pbCommon.AnyValue_StringValue:
+		return utf8.RuneCountInString(v.StringValue)
+	case *pbCommon.AnyValue_ArrayValue:
+		longest := 0
+		for _, value := range v.ArrayValue.GetValues() {
+			longest = max(longest, valueLength(value))
+		}
+		return longest
+	}
+	return 0
+}
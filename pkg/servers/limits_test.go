@@ -0,0 +1,20 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestCheckLimits(t *testing.T) {
+	attrs := []*pbCommon.KeyValue{
+		stringKV("http.route", "/users"),
+		stringKV("url.full", "https://example.com/ü"),
+		intKV("http.status_code", 200),
+	}
+	assert.Empty(t, checkLimits(slog.Default(), Limits{}, attrs))
+	assert.Empty(t, checkLimits(slog.Default(), Limits{AttributeCount: 3, ValueLength: 21}, attrs))
+	assert.Equal(t, []string{limitAttributeCount, "url.full"}, checkLimits(slog.Default(), Limits{AttributeCount: 2, ValueLength: 20}, attrs))
+}
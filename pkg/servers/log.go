@@ -0,0 +1,148 @@
+package servers
+
+import (
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+const redacted = "***"
+
+type redactor struct {
+	keys   map[string]bool
+	values []*regexp.Regexp
+}
+
+func newRedactor(cfg Redact) redactor {
+	r := redactor{keys: map[string]bool{}}
+	for _, key := range cfg.Keys {
+		r.keys[key] = true
+	}
+	for _, value := range cfg.Values {
+		r.values = append(r.values, regexp.MustCompile(value))
+	}
+	return r
+}
+
+func (r redactor) redact(key, value string) string {
+	if r.keys[key] {
+		return redacted
+	}
+	for _, reg := range r.values {
+		if reg.MatchString(value) {
+			return redacted
+		}
+	}
+	return value
+}
+
+// RenameKeys returns a slog.HandlerOptions ReplaceAttr that applies the key
+// mapping.
+func RenameKeys(keys []LogKey) func(groups []string, a slog.Attr) slog.Attr {
+	rename := map[string]string{}
+	for _, k := range keys {
+		rename[k.From] = k.To
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+		if to, ok := rename[a.Key]; ok {
+			a.Key = to
+		}
+		return a
+	}
+}
+
+type reporter struct {
+	diff      bool
+	logValues bool
+	redact    redactor
+}
+
+func newReporter(cfg Config) reporter {
+	return reporter{
+		diff:      cfg.LogFormat == LogDiff,
+		logValues: cfg.LogValues,
+		redact:    newRedactor(cfg.Redact),
+	}
+}
+
+func (r reporter) logAttributes(log *slog.Logger, missing, extra []string, attributes []*pbCommon.KeyValue) {
+	if r.diff {
+		r.logDiff(log, missing, extra, attributes)
+		return
+	}
+	if len(missing) > 0 {
+		log.Info("missing attributes",
+			slog.String("kind", ViolationMissing),
+			slog.Int("count", len(missing)),
+			slog.Any("attributes", missing),
+		)
+	}
+	if len(extra) > 0 {
+		args := []any{
+			slog.String("kind", ViolationExtra),
+			slog.Int("count", len(extra)),
+			slog.Any("attributes", extra),
+		}
+		if r.logValues {
+			args = append(args, slog.Group("values", r.values(extra, attributes)...))
+		}
+		log.Info("extra attributes", args...)
+	}
+}
+
+func (r reporter) logDiff(log *slog.Logger, missing, extra []string, attributes []*pbCommon.KeyValue) {
+	if len(missing) == 0 && len(extra) == 0 {
+		return
+	}
+	diff := make([]string, 0, len(missing)+len(extra))
+	for _, e := range extra {
+		diff = append(diff, "+"+e)
+	}
+	for _, m := range missing {
+		diff = append(diff, "-"+m)
+	}
+	args := []any{slog.String("diff", strings.Join(diff, " "))}
+	if r.logValues && len(extra) > 0 {
+		args = append(args, slog.Group("values", r.values(extra, attributes)...))
+	}
+	log.Info("attributes", args...)
+}
+
+func (r reporter) values(keys []string, attributes []*pbCommon.KeyValue) []any {
+	values := []any{}
+	for _, key := range keys {
+		for _, kv := range attributes {
+			if kv.Key == key {
+				values = append(values, slog.String(key, r.redact.redact(key, valueString(kv.Value))))
+				break
+			}
+		}
+	}
+	return values
+}
+
+func valueString(v *pbCommon.AnyValue) string {
+	switch v := v.GetValue().(type) {
+	case *pbCommon.AnyValue_StringValue:
+		return v.StringValue
+	case *pbCommon.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *pbCommon.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *pbCommon.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case *pbCommon.AnyValue_ArrayValue:
+		values := []string{}
+		for _, value := range v.ArrayValue.GetValues() {
+			values = append(values, valueString(value))
+		}
+		return "[" + strings.Join(values, " ") + "]"
+	}
+	return ""
+}
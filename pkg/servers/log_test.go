@@ -0,0 +1,35 @@
+package servers
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestRedactValues(t *testing.T) {
+	r := newReporter(Config{
+		LogValues: true,
+		Redact:    Redact{Keys: []string{"user.email"}, Values: []string{"^Bearer "}},
+	})
+	attributes := []*pbCommon.KeyValue{
+		stringKV("user.email", "someone@example.com"),
+		stringKV("http.request.header.authorization", "Bearer abc123"),
+		stringKV("http.route", "/users"),
+	}
+	extra := []string{"user.email", "http.request.header.authorization", "http.route"}
+
+	for _, format := range []LogFormat{LogText, LogDiff} {
+		r.diff = format == LogDiff
+		buf := &bytes.Buffer{}
+		r.logAttributes(slog.New(slog.NewTextHandler(buf, nil)), nil, extra, attributes)
+		out := buf.String()
+		assert.Contains(t, out, "values.user.email=***", format)
+		assert.Contains(t, out, "values.http.request.header.authorization=***", format)
+		assert.Contains(t, out, "values.http.route=/users", format)
+		assert.NotContains(t, out, "someone@example.com", format)
+		assert.NotContains(t, out, "abc123", format)
+	}
+}
@@ -0,0 +1,167 @@
+package servers
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pbCollectorLog "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+	pbLog "go.opentelemetry.io/proto/otlp/logs/v1"
+	pbResource "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	defaultLogExportInterval = 5 * time.Second
+	maxLogExportBatch        = 512
+	logExportTimeout         = 10 * time.Second
+)
+
+// LogExporter is a Sink that sends violations as OTLP log records to a
+// collector, each under the resource of the service it was found in. Like the
+// AuditLog, writes are queued and violations are dropped when it is full or
+// written after Close.
+type LogExporter struct {
+	conn     *grpc.ClientConn
+	client   pbCollectorLog.LogsServiceClient
+	mu       sync.RWMutex
+	closed   bool
+	queue    chan Violation
+	dropped  atomic.Uint64
+	interval time.Duration
+	done     chan struct{}
+}
+
+func NewLogExporter(cfg LogExportConfig) (*LogExporter, error) {
+	creds := credentials.NewTLS(&tls.Config{})
+	if cfg.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultAuditQueueSize
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultLogExportInterval
+	}
+	e := &LogExporter{
+		conn:     conn,
+		client:   pbCollectorLog.NewLogsServiceClient(conn),
+		queue:    make(chan Violation, size),
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+func (e *LogExporter) Write(v Violation) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		e.dropped.Add(1)
+		return
+	}
+	select {
+	case e.queue <- v:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Close sends the queued violations and closes the connection.
+func (e *LogExporter) Close() error {
+	e.mu.Lock()
+	e.closed = true
+	close(e.queue)
+	e.mu.Unlock()
+	<-e.done
+	if dropped := e.dropped.Load(); dropped > 0 {
+		slog.Warn("dropped exported violations", slog.Uint64("dropped", dropped))
+	}
+	return e.conn.Close()
+}
+
+func (e *LogExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	batch := []Violation{}
+	for {
+		select {
+		case v, ok := <-e.queue:
+			if !ok {
+				e.send(batch)
+				return
+			}
+			batch = append(batch, v)
+			if len(batch) < maxLogExportBatch {
+				continue
+			}
+		case <-ticker.C:
+		}
+		e.send(batch)
+		batch = batch[:0]
+	}
+}
+
+func (e *LogExporter) send(batch []Violation) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), logExportTimeout)
+	defer cancel()
+	_, err := e.client.Export(ctx, violationLogs(batch))
+	if err != nil {
+		slog.Error("failed to export violations", slog.Int("count", len(batch)), slog.String("error", err.Error()))
+	}
+}
+
+func violationLogs(batch []Violation) *pbCollectorLog.ExportLogsServiceRequest {
+	req := &pbCollectorLog.ExportLogsServiceRequest{}
+	byService := map[string]*pbLog.ScopeLogs{}
+	for _, v := range batch {
+		scope, ok := byService[v.Service]
+		if !ok {
+			scope = &pbLog.ScopeLogs{Scope: &pbCommon.InstrumentationScope{Name: "otel-semconv-checker"}}
+			byService[v.Service] = scope
+			req.ResourceLogs = append(req.ResourceLogs, &pbLog.ResourceLogs{
+				Resource:  &pbResource.Resource{Attributes: []*pbCommon.KeyValue{stringValue("service.name", v.Service)}},
+				ScopeLogs: []*pbLog.ScopeLogs{scope},
+			})
+		}
+		severity, text := pbLog.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+		if v.Advisory {
+			severity, text = pbLog.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"
+		}
+		scope.LogRecords = append(scope.LogRecords, &pbLog.LogRecord{
+			TimeUnixNano:   uint64(v.Time.UnixNano()),
+			SeverityNumber: severity,
+			SeverityText:   text,
+			Body:           &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: "semantic convention violation"}},
+			Attributes: []*pbCommon.KeyValue{
+				stringValue("violation.kind", v.Kind),
+				stringValue("violation.attribute", v.Attribute),
+				stringValue("violation.signal", v.Signal),
+				stringValue("violation.name", v.Name),
+				{Key: "violation.advisory", Value: &pbCommon.AnyValue{Value: &pbCommon.AnyValue_BoolValue{BoolValue: v.Advisory}}},
+			},
+		})
+	}
+	return req
+}
+
+func stringValue(key, value string) *pbCommon.KeyValue {
+	return &pbCommon.KeyValue{Key: key, Value: &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: value}}}
+}
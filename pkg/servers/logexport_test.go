@@ -0,0 +1,53 @@
+package servers
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbCollectorLog "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+)
+
+type logCollector struct {
+	pbCollectorLog.UnimplementedLogsServiceServer
+	mu   sync.Mutex
+	reqs []*pbCollectorLog.ExportLogsServiceRequest
+}
+
+func (c *logCollector) Export(ctx context.Context, req *pbCollectorLog.ExportLogsServiceRequest) (*pbCollectorLog.ExportLogsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reqs = append(c.reqs, req)
+	return &pbCollectorLog.ExportLogsServiceResponse{}, nil
+}
+
+func TestLogExporter(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	collector := &logCollector{}
+	srv := grpc.NewServer()
+	pbCollectorLog.RegisterLogsServiceServer(srv, collector)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	e, err := NewLogExporter(LogExportConfig{Endpoint: lis.Addr().String(), Insecure: true, Interval: time.Hour})
+	require.NoError(t, err)
+	e.Write(Violation{Time: time.Now(), Service: "svc", Signal: "trace", Name: "GET /", Attribute: "http.route", Kind: ViolationMissing})
+	e.Write(Violation{Time: time.Now(), Service: "svc", Signal: "trace", Name: "GET /", Attribute: "url.path", Kind: ViolationMissing})
+	require.NoError(t, e.Close())
+	e.Write(Violation{Time: time.Now(), Service: "svc", Signal: "trace", Name: "GET /", Attribute: "url.scheme", Kind: ViolationMissing})
+	assert.Equal(t, uint64(1), e.dropped.Load())
+
+	require.Len(t, collector.reqs, 1)
+	resource := collector.reqs[0].ResourceLogs
+	require.Len(t, resource, 1)
+	assert.Equal(t, "svc", resource[0].Resource.Attributes[0].Value.GetStringValue())
+	records := resource[0].ScopeLogs[0].LogRecords
+	require.Len(t, records, 2)
+	assert.Equal(t, "http.route", records[0].Attributes[1].Value.GetStringValue())
+}
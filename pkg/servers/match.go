@@ -0,0 +1,144 @@
+package servers
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// matcher finds which match patterns apply to a name. Patterns that are only
+// literal text, optionally anchored with ^ and $ or ending in .*, are looked
+// up with maps and string comparisons; everything else falls back to running
+// the regular expression.
+type matcher struct {
+	exact map[string][]int
+	// prefixes is keyed by the prefix, lengths are the distinct prefix lengths.
+	prefixes map[string][]int
+	lengths  []int
+	contains []indexed[string]
+	regexps  []indexed[*regexp.Regexp]
+}
+
+type indexed[T any] struct {
+	index int
+	value T
+}
+
+type patternKind int
+
+const (
+	patternRegexp patternKind = iota
+	patternExact
+	patternPrefix
+	patternContains
+)
+
+func newMatcher(patterns []string) matcher {
+	m := matcher{
+		exact:    map[string][]int{},
+		prefixes: map[string][]int{},
+	}
+	for i, pattern := range patterns {
+		kind, lit := literalPattern(pattern)
+		switch kind {
+		case patternExact:
+			m.exact[lit] = append(m.exact[lit], i)
+		case patternPrefix:
+			if _, ok := m.prefixes[lit]; !ok {
+				m.lengths = append(m.lengths, len(lit))
+			}
+			m.prefixes[lit] = append(m.prefixes[lit], i)
+		case patternContains:
+			m.contains = append(m.contains, indexed[string]{i, lit})
+		default:
+			m.regexps = append(m.regexps, indexed[*regexp.Regexp]{i, regexp.MustCompile(pattern)})
+		}
+	}
+	sort.Ints(m.lengths)
+	m.lengths = compactInts(m.lengths)
+	return m
+}
+
+// lookup returns the indexes of the patterns that match name, in order.
+func (m matcher) lookup(name string) []int {
+	found := append([]int(nil), m.exact[name]...)
+	for _, l := range m.lengths {
+		if l > len(name) {
+			break
+		}
+		found = append(found, m.prefixes[name[:l]]...)
+	}
+	for _, c := range m.contains {
+		if strings.Contains(name, c.value) {
+			found = append(found, c.index)
+		}
+	}
+	for _, r := range m.regexps {
+		if r.value.MatchString(name) {
+			found = append(found, r.index)
+		}
+	}
+	sort.Ints(found)
+	return found
+}
+
+// literalPattern reports if pattern can be matched without a regular
+// expression, and the literal text to compare against.
+func literalPattern(pattern string) (patternKind, string) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return patternRegexp, ""
+	}
+	re = re.Simplify()
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+
+	begin, end := false, false
+	if len(subs) > 0 && subs[0].Op == syntax.OpBeginText {
+		begin, subs = true, subs[1:]
+	}
+	if len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText {
+		end, subs = true, subs[:len(subs)-1]
+	} else if len(subs) > 0 && isAnyStar(subs[len(subs)-1]) {
+		// A trailing .* can match the empty string, so it never changes the
+		// result of an unanchored match.
+		subs = subs[:len(subs)-1]
+	}
+
+	lit := ""
+	switch {
+	case len(subs) == 0:
+	case len(subs) == 1 && subs[0].Op == syntax.OpLiteral && subs[0].Flags&syntax.FoldCase == 0:
+		lit = string(subs[0].Rune)
+	case len(subs) == 1 && subs[0].Op == syntax.OpEmptyMatch:
+	default:
+		return patternRegexp, ""
+	}
+
+	switch {
+	case begin && end:
+		return patternExact, lit
+	case begin:
+		return patternPrefix, lit
+	case end:
+		return patternRegexp, ""
+	}
+	return patternContains, lit
+}
+
+func isAnyStar(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpStar && (re.Sub[0].Op == syntax.OpAnyCharNotNL || re.Sub[0].Op == syntax.OpAnyChar)
+}
+
+func compactInts(s []int) []int {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package servers
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPatterns = []string{
+	"http.server.*",
+	`^http\.client\.`,
+	`^http\.client\..*`,
+	`^db\.query$`,
+	`db`,
+	`^rpc`,
+	`(?i)^RPC`,
+	`server$`,
+	"",
+	`^$`,
+	`^rpc\..+`,
+}
+
+func TestLiteralPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		kind    patternKind
+		lit     string
+	}{
+		{"http.server.*", patternRegexp, ""},
+		{`^http\.client\.`, patternPrefix, "http.client."},
+		{`^http\.client\..*`, patternPrefix, "http.client."},
+		{`^db\.query$`, patternExact, "db.query"},
+		{`db`, patternContains, "db"},
+		{`db.*`, patternContains, "db"},
+		{`(?i)^RPC`, patternRegexp, ""},
+		{`server$`, patternRegexp, ""},
+		{"", patternContains, ""},
+		{`^$`, patternExact, ""},
+		{`^rpc\..+`, patternRegexp, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			kind, lit := literalPattern(tt.pattern)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.lit, lit)
+		})
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m := newMatcher(testPatterns)
+	names := []string{
+		"",
+		"http.server.request",
+		"httpXserverX",
+		"http.client.GET",
+		"http.client.",
+		"http.client",
+		"db.query",
+		"db.query.slow",
+		"mydb",
+		"rpc",
+		"RPC.call",
+		"rpc.call",
+		"web server",
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			want := []int{}
+			for i, pattern := range testPatterns {
+				if regexp.MustCompile(pattern).MatchString(name) {
+					want = append(want, i)
+				}
+			}
+			got := m.lookup(name)
+			if got == nil {
+				got = []int{}
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	patterns := []string{}
+	for _, ns := range []string{"db", "http", "rpc", "messaging", "faas", "aws", "gcp", "azure"} {
+		for _, kind := range []string{"client", "server", "producer", "consumer"} {
+			patterns = append(patterns, `^`+ns+`\.`+kind+`\.`)
+		}
+	}
+	name := "messaging.consumer.process"
+
+	b.Run("Matcher", func(b *testing.B) {
+		m := newMatcher(patterns)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m.lookup(name)
+		}
+	})
+	b.Run("Regexp", func(b *testing.B) {
+		regs := []*regexp.Regexp{}
+		for _, p := range patterns {
+			regs = append(regs, regexp.MustCompile(p))
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, r := range regs {
+				r.MatchString(name)
+			}
+		}
+	})
+}
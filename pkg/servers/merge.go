@@ -0,0 +1,96 @@
+package servers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Fragment is one part of a config, Keys are the config keys it sets such as
+// "signals.trace.skip_resource", as listed by viper's AllKeys.
+type Fragment struct {
+	Config Config
+	Keys   []string
+}
+
+// Merge combines config fragments into one Config. Lists are appended, while
+// a setting given different values by two fragments is an error, as is a
+// match pattern or group defined more than once. Settings are only taken from
+// the fragments whose Keys include them, so false and zero values conflict
+// too.
+func Merge(fragments ...Fragment) (Config, error) {
+	cfg := Config{}
+	set := map[string]bool{}
+	for _, f := range fragments {
+		keys := map[string]bool{}
+		for _, k := range f.Keys {
+			keys[strings.ToLower(k)] = true
+		}
+		if err := merge(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(f.Config), "", keys, set); err != nil {
+			return Config{}, err
+		}
+	}
+	for name, matches := range map[string][]Match{"trace": cfg.Trace, "metric": cfg.Metric, "log": cfg.Log} {
+		seen := map[[2]string]bool{}
+		for _, m := range matches {
+			key := [2]string{m.Match, m.Scope}
+			if seen[key] {
+				return Config{}, fmt.Errorf("duplicate %s match %q", name, m.Match)
+			}
+			seen[key] = true
+		}
+	}
+	seen := map[string]bool{}
+	for _, g := range cfg.Groups {
+		if seen[g.Id] {
+			return Config{}, fmt.Errorf("duplicate group %q", g.Id)
+		}
+		seen[g.Id] = true
+	}
+	return cfg, nil
+}
+
+// merge merges src into dst. keys are the keys src sets, set the keys an
+// earlier fragment set.
+func merge(dst, src reflect.Value, path string, keys, set map[string]bool) error {
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			name := configKey(dst.Type().Field(i))
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := merge(dst.Field(i), src.Field(i), name, keys, set); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		dst.Set(reflect.AppendSlice(dst, src))
+	default:
+		if !keys[path] {
+			return nil
+		}
+		if set[path] && !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			return fmt.Errorf("conflicting values for %s: %v and %v", path, indirect(dst), indirect(src))
+		}
+		dst.Set(src)
+		set[path] = true
+	}
+	return nil
+}
+
+// configKey is the key of a config field, its mapstructure tag or, like
+// viper, its lower cased name.
+func configKey(f reflect.StructField) string {
+	if tag, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ","); tag != "" {
+		return tag
+	}
+	return strings.ToLower(f.Name)
+}
+
+func indirect(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		return v.Elem().Interface()
+	}
+	return v.Interface()
+}
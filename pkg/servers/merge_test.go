@@ -0,0 +1,45 @@
+package servers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	yes := true
+	cfg, err := Merge(
+		Fragment{
+			Config: Config{ServerAddress: ":4317", Trace: []Match{{Match: "a"}}, Resource: Match{Groups: []string{"host"}}},
+			Keys:   []string{"server_address", "trace", "resource.groups"},
+		},
+		Fragment{
+			Config: Config{ServerAddress: ":4317", Trace: []Match{{Match: "b"}}, Resource: Match{Groups: []string{"os"}}, Signals: Signals{Trace: SignalConfig{OneShot: &yes}}},
+			Keys:   []string{"server_address", "trace", "resource.groups", "signals.trace.one_shot"},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, ":4317", cfg.ServerAddress)
+	assert.Len(t, cfg.Trace, 2)
+	assert.Equal(t, []string{"host", "os"}, cfg.Resource.Groups)
+	assert.True(t, *cfg.Signals.Trace.OneShot)
+
+	_, err = Merge(
+		Fragment{Config: Config{ServerAddress: ":4317"}, Keys: []string{"server_address"}},
+		Fragment{Config: Config{ServerAddress: ":4318"}, Keys: []string{"server_address"}},
+	)
+	assert.EqualError(t, err, "conflicting values for server_address: :4317 and :4318")
+
+	_, err = Merge(
+		Fragment{Config: Config{OneShot: true, Signals: Signals{Trace: SignalConfig{SkipResource: true}}}, Keys: []string{"one_shot", "signals.trace.skip_resource"}},
+		Fragment{Config: Config{}, Keys: []string{"signals.trace.skip_resource"}},
+	)
+	assert.EqualError(t, err, "conflicting values for signals.trace.skip_resource: true and false")
+
+	_, err = Merge(Fragment{Config: Config{Trace: []Match{{Match: "a"}}}}, Fragment{Config: Config{Trace: []Match{{Match: "a"}}}})
+	assert.ErrorContains(t, err, `duplicate trace match "a"`)
+
+	_, err = Merge(Fragment{Config: Config{Groups: []Group{{Id: "g"}}}}, Fragment{Config: Config{Groups: []Group{{Id: "g"}}}})
+	assert.ErrorContains(t, err, `duplicate group "g"`)
+}
@@ -0,0 +1,145 @@
+package servers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var durationBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Metrics are the checker's own operational metrics, served in the Prometheus
+// text format. A nil *Metrics records nothing.
+type Metrics struct {
+	mu          sync.Mutex
+	exports     map[string]uint64
+	items       map[string]uint64
+	buckets     map[string][]uint64
+	durationSum map[string]float64
+	scores      map[string]score
+	audits      []*AuditLog
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		exports:     map[string]uint64{},
+		items:       map[string]uint64{},
+		buckets:     map[string][]uint64{},
+		durationSum: map[string]float64{},
+		scores:      map[string]score{},
+	}
+}
+
+// WatchAuditLog adds the audit log's queue length and dropped violations to
+// those of any other watched audit logs.
+func (m *Metrics) WatchAuditLog(a *AuditLog) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audits = append(m.audits, a)
+}
+
+// observeExport records one export of signal that took d and held items spans,
+// data points or log records.
+func (m *Metrics) observeExport(signal string, d time.Duration, items int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exports[signal]++
+	m.items[signal] += uint64(items)
+	buckets, ok := m.buckets[signal]
+	if !ok {
+		buckets = make([]uint64, len(durationBuckets))
+		m.buckets[signal] = buckets
+	}
+	seconds := d.Seconds()
+	for i, le := range durationBuckets {
+		if seconds <= le {
+			buckets[i]++
+		}
+	}
+	m.durationSum[signal] += seconds
+}
+
+// addScores accumulates the conformance scores of an export, so the served
+// score covers every span seen since the checker started.
+func (m *Metrics) addScores(scores scores) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for service, sc := range scores {
+		total := m.scores[service]
+		total.required += sc.required
+		total.present += sc.present
+		m.scores[service] = total
+	}
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	signals := sortedKeys(m.exports)
+	fmt.Fprintln(w, "# HELP semconv_checker_exports_total Export calls handled.")
+	fmt.Fprintln(w, "# TYPE semconv_checker_exports_total counter")
+	for _, signal := range signals {
+		fmt.Fprintf(w, "semconv_checker_exports_total{signal=%q} %d\n", signal, m.exports[signal])
+	}
+	fmt.Fprintln(w, "# HELP semconv_checker_items_total Spans, data points or log records checked.")
+	fmt.Fprintln(w, "# TYPE semconv_checker_items_total counter")
+	for _, signal := range signals {
+		fmt.Fprintf(w, "semconv_checker_items_total{signal=%q} %d\n", signal, m.items[signal])
+	}
+	fmt.Fprintln(w, "# HELP semconv_checker_export_duration_seconds Time spent checking an export.")
+	fmt.Fprintln(w, "# TYPE semconv_checker_export_duration_seconds histogram")
+	for _, signal := range signals {
+		for i, le := range durationBuckets {
+			fmt.Fprintf(w, "semconv_checker_export_duration_seconds_bucket{signal=%q,le=%q} %d\n", signal, strconv.FormatFloat(le, 'g', -1, 64), m.buckets[signal][i])
+		}
+		fmt.Fprintf(w, "semconv_checker_export_duration_seconds_bucket{signal=%q,le=\"+Inf\"} %d\n", signal, m.exports[signal])
+		fmt.Fprintf(w, "semconv_checker_export_duration_seconds_sum{signal=%q} %g\n", signal, m.durationSum[signal])
+		fmt.Fprintf(w, "semconv_checker_export_duration_seconds_count{signal=%q} %d\n", signal, m.exports[signal])
+	}
+
+	fmt.Fprintln(w, "# HELP semconv_checker_conformance_score Percentage of required attributes present, per service.")
+	fmt.Fprintln(w, "# TYPE semconv_checker_conformance_score gauge")
+	for _, service := range sortedKeys(m.scores) {
+		fmt.Fprintf(w, "semconv_checker_conformance_score{service_name=%q} %g\n", service, m.scores[service].percent())
+	}
+
+	if len(m.audits) > 0 {
+		length, dropped := 0, uint64(0)
+		for _, a := range m.audits {
+			length += a.Len()
+			dropped += a.Dropped()
+		}
+		fmt.Fprintln(w, "# HELP semconv_checker_audit_queue_length Violations waiting to be written to the audit log.")
+		fmt.Fprintln(w, "# TYPE semconv_checker_audit_queue_length gauge")
+		fmt.Fprintf(w, "semconv_checker_audit_queue_length %d\n", length)
+		fmt.Fprintln(w, "# HELP semconv_checker_audit_dropped_total Violations dropped because the audit log queue was full.")
+		fmt.Fprintln(w, "# TYPE semconv_checker_audit_dropped_total counter")
+		fmt.Fprintf(w, "semconv_checker_audit_dropped_total %d\n", dropped)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func WithMetrics(m *Metrics) Option {
+	return func(s *TraceServer) {
+		s.metrics = m
+	}
+}
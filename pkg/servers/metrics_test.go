@@ -0,0 +1,29 @@
+package servers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.observeExport("trace", 2*time.Millisecond, 3)
+	m.observeExport("trace", time.Second, 2)
+	m.addScores(scores{"svc": {required: 4, present: 3}})
+	m.addScores(scores{"svc": {required: 4, present: 4}})
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `semconv_checker_exports_total{signal="trace"} 2`)
+	assert.Contains(t, body, `semconv_checker_items_total{signal="trace"} 5`)
+	assert.Contains(t, body, `semconv_checker_export_duration_seconds_bucket{signal="trace",le="0.0025"} 1`)
+	assert.Contains(t, body, `semconv_checker_export_duration_seconds_bucket{signal="trace",le="+Inf"} 2`)
+	assert.Contains(t, body, `semconv_checker_conformance_score{service_name="svc"} 87.5`)
+
+	var none *Metrics
+	none.observeExport("trace", time.Second, 1)
+}
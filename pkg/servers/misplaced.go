@@ -0,0 +1,47 @@
+package servers
+
+import (
+	"log/slog"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// placement knows which level semantic convention attributes belong at.
+// Attributes in both resource and span groups are never misplaced.
+type placement struct {
+	resource map[string]bool
+	span     map[string]bool
+}
+
+func newPlacement(g map[string]semconv.Group) placement {
+	p := placement{
+		resource: semconv.AttributesOfType(g, "resource"),
+		span:     semconv.AttributesOfType(g, "span"),
+	}
+	for a := range p.span {
+		if p.resource[a] {
+			delete(p.span, a)
+			delete(p.resource, a)
+		}
+	}
+	return p
+}
+
+// misplaced logs, and returns, the attributes that belong at the other level.
+// elsewhere is the attributes of that level, belongs its name.
+func misplaced(log *slog.Logger, elsewhere map[string]bool, belongs string, attributes []*pbCommon.KeyValue) []string {
+	found := []string{}
+	for _, kv := range attributes {
+		if elsewhere[kv.Key] {
+			found = append(found, kv.Key)
+		}
+	}
+	if len(found) > 0 {
+		log.Info("misplaced attributes",
+			slog.String("belongs", belongs),
+			slog.Any("attributes", found),
+		)
+	}
+	return found
+}
@@ -0,0 +1,23 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestMisplaced(t *testing.T) {
+	g, err := semconv.ParseGroups()
+	require.NoError(t, err)
+	p := newPlacement(g)
+
+	span := []*pbCommon.KeyValue{stringKV("http.route", "/"), stringKV("service.name", "svc")}
+	assert.Equal(t, []string{"service.name"}, misplaced(slog.Default(), p.resource, "resource", span))
+
+	resource := []*pbCommon.KeyValue{stringKV("service.name", "svc"), stringKV("http.route", "/")}
+	assert.Equal(t, []string{"http.route"}, misplaced(slog.Default(), p.span, "span", resource))
+}
@@ -0,0 +1,99 @@
+package servers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	ModeEnforce = "enforce"
+	ModeObserve = "observe"
+)
+
+// Mode switches servers between enforcing violations and only reporting them,
+// and changes the log level, at runtime. It takes effect on the next export.
+type Mode struct {
+	token   string
+	level   *slog.LevelVar
+	servers []*TraceServer
+}
+
+func NewMode(token string, level *slog.LevelVar, servers ...*TraceServer) *Mode {
+	return &Mode{token: token, level: level, servers: servers}
+}
+
+type modeState struct {
+	Mode     string `json:"mode"`
+	LogLevel string `json:"log_level"`
+}
+
+// ServeHTTP serves the mode and log level as JSON. A POST sets them from the
+// mode and log_level form values first. Requests need the token as a bearer
+// token, every change is logged.
+func (m *Mode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(m.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if err := m.set(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.state())
+}
+
+func (m *Mode) set(r *http.Request) error {
+	mode, level := r.FormValue("mode"), r.FormValue("log_level")
+	var observe bool
+	switch mode {
+	case "", ModeEnforce:
+	case ModeObserve:
+		observe = true
+	default:
+		return fmt.Errorf("unknown mode %q, expected enforce or observe", mode)
+	}
+	var l slog.Level
+	if level != "" {
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return err
+		}
+	}
+	if mode != "" {
+		for _, s := range m.servers {
+			s.SetObserve(observe)
+		}
+		slog.Warn("enforcement mode changed", slog.String("mode", mode), slog.String("remote", r.RemoteAddr))
+	}
+	if level != "" {
+		m.level.Set(l)
+		slog.Warn("log level changed", slog.String("log_level", l.String()), slog.String("remote", r.RemoteAddr))
+	}
+	return nil
+}
+
+func (m *Mode) state() modeState {
+	state := modeState{Mode: ModeEnforce, LogLevel: m.level.Level().String()}
+	if len(m.servers) > 0 && m.servers[0].observe.Load() {
+		state.Mode = ModeObserve
+	}
+	return state
+}
+
+// SetObserve makes the server only report violations, accepting every export,
+// or enforce them again.
+func (s *TraceServer) SetObserve(observe bool) {
+	s.observe.Store(observe)
+}
@@ -0,0 +1,44 @@
+package servers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestMode(t *testing.T) {
+	s := newTraceService(t, acmeConfig(Match{Match: ".*", Groups: []string{"acme"}}), acmeGroups())
+	level := &slog.LevelVar{}
+	mode := NewMode("secret", level, s)
+
+	post := func(token string, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mode", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		mode.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("wrong", url.Values{"mode": {"observe"}}).Code)
+	_, err := exportSpans(s, &pbTrace.Span{Name: "GET /"})
+	assert.Error(t, err)
+
+	w := post("secret", url.Values{"mode": {"observe"}, "log_level": {"debug"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"mode":"observe","log_level":"DEBUG"}`, w.Body.String())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	_, err = exportSpans(s, &pbTrace.Span{Name: "GET /"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, post("secret", url.Values{"mode": {"off"}}).Code)
+	assert.Equal(t, http.StatusOK, post("secret", url.Values{"mode": {"enforce"}}).Code)
+	_, err = exportSpans(s, &pbTrace.Span{Name: "GET /"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	pbLog "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	pbMetric "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	pbTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// RegisterAll registers the trace, metric and log services on s, for embedding
+// the checker in another grpc server. The returned TraceServer's Done is used
+// in one shot mode.
+func RegisterAll(s *grpc.Server, cfg Config, g map[string]semconv.Group, opts ...Option) (*TraceServer, error) {
+	traceServer, err := NewTraceService(cfg, g, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pbTrace.RegisterTraceServiceServer(s, traceServer)
+	pbMetric.RegisterMetricsServiceServer(s, &metricServer{g: g})
+	pbLog.RegisterLogsServiceServer(s, &logServer{g: g})
+	return traceServer, nil
+}
+
+type metricServer struct {
+	pbMetric.UnimplementedMetricsServiceServer
+	g map[string]semconv.Group
+}
+
+func (s *metricServer) Export(ctx context.Context, req *pbMetric.ExportMetricsServiceRequest) (*pbMetric.ExportMetricsServiceResponse, error) {
+	return nil, nil
+}
+
+type logServer struct {
+	pbLog.UnimplementedLogsServiceServer
+	g map[string]semconv.Group
+}
+
+func (s *logServer) Export(ctx context.Context, req *pbLog.ExportLogsServiceRequest) (*pbLog.ExportLogsServiceResponse, error) {
+	return nil, nil
+}
@@ -0,0 +1,128 @@
+package servers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	ReportJSON  = "json"
+	ReportJUnit = "junit"
+)
+
+// Report is a Sink that keeps every violation, to be written as one file when
+// the checker exits.
+type Report struct {
+	cfg        ReportConfig
+	mu         sync.Mutex
+	violations []Violation
+}
+
+func NewReport(cfg ReportConfig) (*Report, error) {
+	switch cfg.Format {
+	case ReportJSON, ReportJUnit, "":
+	default:
+		return nil, fmt.Errorf("unknown report format %q", cfg.Format)
+	}
+	return &Report{cfg: cfg}, nil
+}
+
+func (r *Report) Write(v Violation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.violations = append(r.violations, v)
+}
+
+// WriteFile writes the report to the configured path as JSON, or JUnit XML
+// where each span with violations is a test case.
+func (r *Report) WriteFile() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var data []byte
+	var err error
+	if r.cfg.Format == ReportJUnit {
+		data, err = xml.MarshalIndent(r.junit(), "", "  ")
+		data = append([]byte(xml.Header), data...)
+	} else {
+		data, err = json.MarshalIndent(jsonReport{
+			Violations: r.violations,
+			Enforced:   enforced(r.violations),
+		}, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.cfg.Path, append(data, '\n'), 0o644)
+}
+
+type jsonReport struct {
+	Violations []Violation `json:"violations"`
+	Enforced   int         `json:"enforced"`
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit groups the violations by service, signal and name, in the order they
+// were first seen. Advisory violations do not fail a case.
+func (r *Report) junit() junitSuite {
+	type key struct{ service, signal, name string }
+	index := map[key]int{}
+	enforcedLines, advisoryLines := [][]string{}, [][]string{}
+	suite := junitSuite{Name: "otel-semconv-checker"}
+	for _, v := range r.violations {
+		k := key{v.Service, v.Signal, v.Name}
+		i, ok := index[k]
+		if !ok {
+			i = len(suite.Cases)
+			index[k] = i
+			suite.Cases = append(suite.Cases, junitCase{Name: v.Name, ClassName: v.Service + "." + v.Signal})
+			enforcedLines = append(enforcedLines, nil)
+			advisoryLines = append(advisoryLines, nil)
+		}
+		line := v.Kind + ": " + v.Attribute
+		if v.Match != "" {
+			line += " (match " + v.Match + ")"
+		}
+		if v.Advisory {
+			advisoryLines[i] = append(advisoryLines[i], line)
+		} else {
+			enforcedLines[i] = append(enforcedLines[i], line)
+		}
+	}
+	for i := range suite.Cases {
+		if len(enforcedLines[i]) > 0 {
+			suite.Cases[i].Failure = &junitFailure{
+				Message: fmt.Sprintf("%d violations", len(enforcedLines[i])),
+				Text:    strings.Join(enforcedLines[i], "\n"),
+			}
+			suite.Failures++
+		}
+		if len(advisoryLines[i]) > 0 {
+			suite.Cases[i].SystemOut = "advisory\n" + strings.Join(advisoryLines[i], "\n")
+		}
+	}
+	suite.Tests = len(suite.Cases)
+	return suite
+}
@@ -0,0 +1,28 @@
+package servers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportJUnit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xml")
+	r, err := NewReport(ReportConfig{Path: path, Format: ReportJUnit})
+	require.NoError(t, err)
+	r.Write(Violation{Service: "svc", Signal: "trace", Name: "GET /", Attribute: "http.route", Kind: ViolationMissing})
+	r.Write(Violation{Service: "svc", Signal: "trace", Name: "GET /", Attribute: "url.path", Kind: ViolationMissing})
+	r.Write(Violation{Service: "svc", Signal: "trace", Name: "POST /", Attribute: "url.path", Kind: ViolationMissing, Advisory: true})
+	require.NoError(t, r.WriteFile())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<testsuite name="otel-semconv-checker" tests="2" failures="1">`)
+	assert.Contains(t, string(data), `<failure message="2 violations">missing: http.route&#xA;missing: url.path</failure>`)
+
+	_, err = NewReport(ReportConfig{Format: "html"})
+	assert.Error(t, err)
+}
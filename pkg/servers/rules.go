@@ -0,0 +1,85 @@
+package servers
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+type rule struct {
+	key      string
+	value    *regexp.Regexp
+	resource bool
+	then     []string
+}
+
+func newRules(cfg []Rule) []rule {
+	rules := []rule{}
+	for _, r := range cfg {
+		rl := rule{key: r.If, resource: r.Resource, then: r.Then}
+		if r.Value != "" {
+			rl.value = regexp.MustCompile(r.Value)
+		}
+		rules = append(rules, rl)
+	}
+	return rules
+}
+
+func (r rule) String() string {
+	key := r.key
+	if r.resource {
+		key = "resource " + key
+	}
+	if r.value != nil {
+		return fmt.Sprintf("if %s =~ %s then %v", key, r.value, r.then)
+	}
+	return fmt.Sprintf("if %s then %v", key, r.then)
+}
+
+// applies reports if the rule's condition holds for the attributes. Like a
+// missing attribute, one without a value does not hold.
+func (r rule) applies(attributes []*pbCommon.KeyValue) bool {
+	for _, kv := range attributes {
+		if kv.Key != r.key || kv.GetValue().GetValue() == nil {
+			continue
+		}
+		return r.value == nil || r.value.MatchString(valueString(kv.Value))
+	}
+	return false
+}
+
+func (r rule) missing(attributes []*pbCommon.KeyValue) []string {
+	missing := []string{}
+OUTER:
+	for _, then := range r.then {
+		for _, kv := range attributes {
+			if kv.Key == then && kv.GetValue().GetValue() != nil {
+				continue OUTER
+			}
+		}
+		missing = append(missing, then)
+	}
+	return missing
+}
+
+// checkRules logs each rule that applies to the attributes, or for resource
+// rules the resource attributes, but is not satisfied, and returns the
+// attributes missing because of them.
+func checkRules(log *slog.Logger, rules []rule, attributes, resource []*pbCommon.KeyValue) []string {
+	failed := []string{}
+	for _, r := range rules {
+		if r.resource && !r.applies(resource) || !r.resource && !r.applies(attributes) {
+			continue
+		}
+		if missing := r.missing(attributes); len(missing) > 0 {
+			log.Info("failed rule",
+				slog.String("rule", r.String()),
+				slog.Any("attributes", missing),
+			)
+			failed = append(failed, missing...)
+		}
+	}
+	return failed
+}
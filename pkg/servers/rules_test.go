@@ -0,0 +1,92 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func intKV(key string, value int64) *pbCommon.KeyValue {
+	return &pbCommon.KeyValue{
+		Key: key,
+		Value: &pbCommon.AnyValue{
+			Value: &pbCommon.AnyValue_IntValue{
+				IntValue: value,
+			},
+		},
+	}
+}
+
+func stringKV(key, value string) *pbCommon.KeyValue {
+	return &pbCommon.KeyValue{
+		Key: key,
+		Value: &pbCommon.AnyValue{
+			Value: &pbCommon.AnyValue_StringValue{
+				StringValue: value,
+			},
+		},
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules := newRules([]Rule{
+		{If: "http.response.status_code", Value: "^5", Then: []string{"error.type"}},
+		{If: "error.type", Then: []string{"exception.type", "exception.message"}},
+	})
+	tests := []struct {
+		name       string
+		attributes []*pbCommon.KeyValue
+		want       [][]string
+	}{
+		{
+			name:       "Value does not match",
+			attributes: []*pbCommon.KeyValue{intKV("http.response.status_code", 200)},
+			want:       [][]string{nil, nil},
+		},
+		{
+			name:       "Value matches",
+			attributes: []*pbCommon.KeyValue{intKV("http.response.status_code", 503)},
+			want:       [][]string{{"error.type"}, nil},
+		},
+		{
+			name: "Presence",
+			attributes: []*pbCommon.KeyValue{
+				intKV("http.response.status_code", 503),
+				stringKV("error.type", "timeout"),
+				stringKV("exception.type", "Timeout"),
+			},
+			want: [][]string{{}, {"exception.message"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, r := range rules {
+				if tt.want[i] == nil {
+					assert.False(t, r.applies(tt.attributes), r.String())
+					continue
+				}
+				assert.True(t, r.applies(tt.attributes), r.String())
+				assert.ElementsMatch(t, tt.want[i], r.missing(tt.attributes), r.String())
+			}
+		})
+	}
+}
+
+func TestRulesNullValue(t *testing.T) {
+	rules := newRules([]Rule{{If: "error.type", Then: []string{"exception.type"}}})
+	assert.False(t, rules[0].applies([]*pbCommon.KeyValue{{Key: "error.type"}}))
+	assert.False(t, rules[0].applies([]*pbCommon.KeyValue{{Key: "error.type", Value: &pbCommon.AnyValue{}}}))
+}
+
+func TestResourceRules(t *testing.T) {
+	rules := newRules([]Rule{
+		{If: "cloud.provider", Resource: true, Then: []string{"cloud.region"}},
+	})
+	aws := []*pbCommon.KeyValue{stringKV("cloud.provider", "aws")}
+
+	assert.Equal(t, []string{"cloud.region"}, checkRules(slog.Default(), rules, nil, aws))
+	assert.Empty(t, checkRules(slog.Default(), rules, []*pbCommon.KeyValue{stringKV("cloud.region", "eu-west-1")}, aws))
+	assert.Empty(t, checkRules(slog.Default(), rules, aws, nil))
+}
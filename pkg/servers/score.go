@@ -0,0 +1,62 @@
+package servers
+
+import (
+	"log/slog"
+
+	pbResource "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+const unknownService = "unknown_service"
+
+// score is the conformance of a service: the percentage of required
+// attributes that were present across all of its matched spans. Each matched
+// span adds the size of the attribute set it was checked against, after
+// ignores, to required and the number of those it had to present. Resource
+// attributes are not part of the score. A service with nothing required scores
+// 100.
+type score struct {
+	required int
+	present  int
+}
+
+func (s score) percent() float64 {
+	if s.required == 0 {
+		return 100
+	}
+	return 100 * float64(s.present) / float64(s.required)
+}
+
+type scores map[string]*score
+
+func (s scores) add(service string, required, missing int) {
+	sc, ok := s[service]
+	if !ok {
+		sc = &score{}
+		s[service] = sc
+	}
+	sc.required += required
+	sc.present += required - missing
+}
+
+func (s scores) log(log *slog.Logger) {
+	for _, service := range sortedKeys(s) {
+		sc := s[service]
+		log.Info("conformance score",
+			slog.String("service.name", service),
+			slog.Float64("score", sc.percent()),
+			slog.Int("required", sc.required),
+			slog.Int("present", sc.present),
+		)
+	}
+}
+
+func serviceName(r *pbResource.Resource) string {
+	for _, kv := range r.GetAttributes() {
+		if kv.Key == "service.name" {
+			if name := kv.GetValue().GetStringValue(); name != "" {
+				return name
+			}
+		}
+	}
+	return unknownService
+}
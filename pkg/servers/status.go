@@ -0,0 +1,26 @@
+package servers
+
+import (
+	"log/slog"
+
+	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// statusMessage is the attribute of status violations.
+const statusMessage = "status.message"
+
+// checkStatus logs, and returns as status.message, a span status that does not
+// follow the conventions: an error should describe itself in the message, and
+// ok or unset spans should not set one.
+func checkStatus(log *slog.Logger, status *pbTrace.Status) []string {
+	code, message := status.GetCode(), status.GetMessage()
+	switch {
+	case code == pbTrace.Status_STATUS_CODE_ERROR && message == "":
+		log.Info("error status without a message")
+	case code != pbTrace.Status_STATUS_CODE_ERROR && message != "":
+		log.Info("status message without an error", slog.String("code", code.String()))
+	default:
+		return nil
+	}
+	return []string{statusMessage}
+}
@@ -0,0 +1,28 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestCheckStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *pbTrace.Status
+		want   []string
+	}{
+		{name: "Unset", status: nil},
+		{name: "Error with message", status: &pbTrace.Status{Code: pbTrace.Status_STATUS_CODE_ERROR, Message: "timeout"}},
+		{name: "Error without message", status: &pbTrace.Status{Code: pbTrace.Status_STATUS_CODE_ERROR}, want: []string{statusMessage}},
+		{name: "Ok with message", status: &pbTrace.Status{Code: pbTrace.Status_STATUS_CODE_OK, Message: "fine"}, want: []string{statusMessage}},
+		{name: "Unset with message", status: &pbTrace.Status{Message: "fine"}, want: []string{statusMessage}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkStatus(slog.Default(), tt.status))
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
 	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
-	pbResource "go.opentelemetry.io/proto/otlp/resource/v1"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
 	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -18,47 +22,323 @@ import (
 type TraceServer struct {
 	pbCollectorTrace.UnimplementedTraceServiceServer
 
-	resourceVersion string
+	skipResource    bool
+	exclude         []expectation
+	versions        versionCheck
+	versionPolicy   Policy
+	scopePolicy     Policy
+	reportMissing   bool
+	checkGroups     bool
+	groupsVersion   string
+	missingLevel    slog.Level
 	resourceGroups  []string
 	resourceIgnore  []string
+	resourceAllowed []string
+	resourceExtra   Policy
+	scopeGroups     []string
+	scopeIgnore     []string
+	scopeAllowed    []string
+	scopeExtra      Policy
 	matches         []traceMatch
+	matcher         matcher
+	hasScope        bool
+	hasPriority     bool
+	hits            []atomic.Uint64
+	observe         atomic.Bool
 	reportUnmatched bool
 	oneShot         bool
+	warmup          time.Duration
+	warmupUntil     time.Time
+	aliases         aliases
+	limits          Limits
+	threshold       Threshold
+	misplaced       Policy
+	statusMessage   Policy
+	placement       placement
+	reporter        reporter
+	grpc            GRPCConfig
+	sinks           []Sink
+	metrics         *Metrics
+	preExport       PreExportHook
+	postExport      PostExportHook
+
+	// done receives the exit code of the first export in one shot mode.
+	done     chan int
+	doneOnce sync.Once
 }
 
 type traceMatch struct {
-	match  *regexp.Regexp
-	group  []string
-	ignore []string
+	pattern string
+	scope   *regexp.Regexp
+	// Each set is the attributes of the groups merged with one of the any_of
+	// groups. A span only has to satisfy one of them.
+	groups [][]string
+	// byKind holds the sets for each span kind, without the groups that are for
+	// other kinds. It is nil if none of the groups have a span kind.
+	byKind map[pbTrace.Span_SpanKind][][]string
+	// otherKind holds, for client and server, producer and consumer spans, the
+	// attributes only the opposite kind's groups have.
+	otherKind map[pbTrace.Span_SpanKind][]string
+	ignore    []string
+	// Extra attributes that are expected for this match. Unlike ignore they are
+	// only removed from the extra attributes.
+	allowed    []string
+	extra      Policy
+	rules      []rule
+	values     []expectation
+	advisory   bool
+	maxChecked int
+	priority   int
 }
 
-func NewTraceService(cfg Config, g map[string]semconv.Group) *TraceServer {
+// NewTraceService returns the trace server for the config, or the errors of
+// Config.Validate.
+func NewTraceService(cfg Config, g map[string]semconv.Group, opts ...Option) (*TraceServer, error) {
+	if err := cfg.Validate(g); err != nil {
+		return nil, err
+	}
 	resourceGroups := []semconv.Group{}
 	for _, group := range cfg.Resource.Groups {
 		resourceGroups = append(resourceGroups, g[group])
 	}
+	scopeGroups := []semconv.Group{}
+	for _, group := range cfg.ScopeAttributes.Groups {
+		scopeGroups = append(scopeGroups, g[group])
+	}
 	matches := []traceMatch{}
+	patterns := []string{}
+	hasScope, hasPriority := false, false
 	for _, match := range cfg.Trace {
-		reg := regexp.MustCompile(match.Match)
+		patterns = append(patterns, match.Match)
 		groups := []semconv.Group{}
 		for _, group := range match.Groups {
 			groups = append(groups, g[group])
 		}
+		var scope *regexp.Regexp
+		if match.Scope != "" {
+			scope = regexp.MustCompile(match.Scope)
+			hasScope = true
+		}
+		hasPriority = hasPriority || match.Priority != 0
+		byKind := kindAttributeSets(g, groups, match.AnyOf)
 		matches = append(matches, traceMatch{
-			match:  reg,
-			group:  semconv.GetAttributes(groups...),
-			ignore: match.Ignore,
+			pattern:    match.Match,
+			scope:      scope,
+			groups:     attributeSets(g, groups, match.AnyOf, ""),
+			byKind:     byKind,
+			otherKind:  otherKindAttributes(byKind),
+			ignore:     match.Ignore,
+			allowed:    match.AllowedExtra,
+			extra:      match.Extra.or(PolicyWarn),
+			rules:      newRules(match.Rules),
+			values:     newExpectations(match.Values),
+			advisory:   match.Advisory,
+			maxChecked: match.MaxChecked,
+			priority:   match.Priority,
 		})
 	}
 
-	return &TraceServer{
-		resourceVersion: semconv.Version,
+	resourceVersion := cfg.Schema.URL
+	if resourceVersion == "" {
+		resourceVersion = semconv.Version
+	}
+
+	versions, err := newVersionCheck(resourceVersion, cfg.Schema.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &TraceServer{
+		skipResource:    cfg.Signals.Trace.SkipResource,
+		exclude:         newExpectations(cfg.Exclude),
+		versions:        versions,
+		versionPolicy:   cfg.Schema.Mismatch,
+		scopePolicy:     cfg.Schema.ScopeMismatch.or(cfg.Schema.Mismatch),
+		reportMissing:   cfg.Schema.ReportMissing,
+		checkGroups:     cfg.Schema.CheckGroups,
+		groupsVersion:   semconv.Version,
+		missingLevel:    parseLevel(cfg.Schema.MissingLevel, slog.LevelWarn),
 		resourceGroups:  semconv.GetAttributes(resourceGroups...),
 		resourceIgnore:  cfg.Resource.Ignore,
+		resourceAllowed: cfg.Resource.AllowedExtra,
+		resourceExtra:   cfg.Resource.Extra.or(PolicyWarn),
+		scopeGroups:     semconv.GetAttributes(scopeGroups...),
+		scopeIgnore:     cfg.ScopeAttributes.Ignore,
+		scopeAllowed:    cfg.ScopeAttributes.AllowedExtra,
+		scopeExtra:      cfg.ScopeAttributes.Extra.or(PolicyWarn),
 		matches:         matches,
-		reportUnmatched: cfg.ReportUnmatched,
-		oneShot:         cfg.OneShot,
+		matcher:         newMatcher(patterns),
+		hasScope:        hasScope,
+		hasPriority:     hasPriority,
+		hits:            make([]atomic.Uint64, len(matches)),
+		reportUnmatched: boolOr(cfg.Signals.Trace.ReportUnmatched, cfg.ReportUnmatched),
+		oneShot:         boolOr(cfg.Signals.Trace.OneShot, cfg.OneShot),
+		warmup:          cfg.Warmup,
+		warmupUntil:     time.Now().Add(cfg.Warmup),
+		aliases:         newAliases(cfg.Aliases),
+		limits:          cfg.Limits,
+		threshold:       cfg.Threshold,
+		misplaced:       cfg.Misplaced.or(PolicyIgnore),
+		statusMessage:   cfg.StatusMessage.or(PolicyIgnore),
+		placement:       newPlacement(g),
+		reporter:        newReporter(cfg),
+		grpc:            cfg.GRPC,
+		done:            make(chan int, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.logEffective()
+	return s, nil
+}
+
+// StartWarmup restarts the warmup from now. The warmup otherwise starts when
+// the server is created, call it just before serving when that is much later.
+func (s *TraceServer) StartWarmup() {
+	s.warmupUntil = time.Now().Add(s.warmup)
+}
+
+// logEffective logs, at debug, the matches as they were resolved from the
+// config and groups.
+func (s *TraceServer) logEffective() {
+	log := slog.With("type", "trace")
+	if !log.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	log.Debug("effective resource",
+		slog.Bool("skip", s.skipResource),
+		slog.String("versions", s.versions.String()),
+		slog.Any("attributes", s.resourceGroups),
+		slog.Any("ignore", s.resourceIgnore),
+		slog.Any("allowed", s.resourceAllowed),
+		slog.String("extra", string(s.resourceExtra)),
+	)
+	for _, m := range s.matches {
+		rules := []string{}
+		for _, r := range m.rules {
+			rules = append(rules, r.String())
+		}
+		args := []any{
+			slog.String("match", m.pattern),
+			slog.Any("sets", m.groups),
+			slog.Any("ignore", m.ignore),
+			slog.Any("allowed", m.allowed),
+			slog.String("extra", string(m.extra)),
+			slog.Any("rules", rules),
+			slog.Bool("advisory", m.advisory),
+		}
+		if m.scope != nil {
+			args = append(args, slog.String("scope", m.scope.String()))
+		}
+		for kind := pbTrace.Span_SPAN_KIND_INTERNAL; kind <= pbTrace.Span_SPAN_KIND_CONSUMER; kind++ {
+			if sets, ok := m.byKind[kind]; ok {
+				args = append(args, slog.Any("sets."+spanKinds[kind], sets))
+			}
+		}
+		log.Debug("effective match", args...)
+	}
+	log.Debug("effective enforcement",
+		slog.Bool("one_shot", s.oneShot),
+		slog.Time("warmup_until", s.warmupUntil),
+		slog.Int("threshold.count", s.threshold.Count),
+		slog.Float64("threshold.percent", s.threshold.Percent),
+	)
+}
+
+var spanKinds = map[pbTrace.Span_SpanKind]string{
+	pbTrace.Span_SPAN_KIND_INTERNAL: "internal",
+	pbTrace.Span_SPAN_KIND_SERVER:   "server",
+	pbTrace.Span_SPAN_KIND_CLIENT:   "client",
+	pbTrace.Span_SPAN_KIND_PRODUCER: "producer",
+	pbTrace.Span_SPAN_KIND_CONSUMER: "consumer",
+}
+
+// attributeSets returns the attributes of the groups merged with each of the
+// any_of groups. When kind is set groups for other span kinds are left out.
+func attributeSets(g map[string]semconv.Group, groups []semconv.Group, anyOf []string, kind string) [][]string {
+	groups = forKind(groups, kind)
+	sets := [][]string{}
+	for _, name := range anyOf {
+		if len(forKind([]semconv.Group{g[name]}, kind)) == 0 {
+			continue
+		}
+		set := append([]semconv.Group{g[name]}, groups...)
+		sets = append(sets, semconv.GetAttributes(set...))
+	}
+	if len(sets) == 0 {
+		return [][]string{semconv.GetAttributes(groups...)}
+	}
+	return sets
+}
+
+func kindAttributeSets(g map[string]semconv.Group, groups []semconv.Group, anyOf []string) map[pbTrace.Span_SpanKind][][]string {
+	hasKind := false
+	for _, group := range groups {
+		hasKind = hasKind || group.SpanKind != ""
+	}
+	for _, name := range anyOf {
+		hasKind = hasKind || g[name].SpanKind != ""
+	}
+	if !hasKind {
+		return nil
+	}
+	byKind := map[pbTrace.Span_SpanKind][][]string{}
+	for k, kind := range spanKinds {
+		byKind[k] = attributeSets(g, groups, anyOf, kind)
+	}
+	return byKind
+}
+
+var oppositeKinds = map[pbTrace.Span_SpanKind]pbTrace.Span_SpanKind{
+	pbTrace.Span_SPAN_KIND_SERVER:   pbTrace.Span_SPAN_KIND_CLIENT,
+	pbTrace.Span_SPAN_KIND_CLIENT:   pbTrace.Span_SPAN_KIND_SERVER,
+	pbTrace.Span_SPAN_KIND_PRODUCER: pbTrace.Span_SPAN_KIND_CONSUMER,
+	pbTrace.Span_SPAN_KIND_CONSUMER: pbTrace.Span_SPAN_KIND_PRODUCER,
+}
+
+func otherKindAttributes(byKind map[pbTrace.Span_SpanKind][][]string) map[pbTrace.Span_SpanKind][]string {
+	if byKind == nil {
+		return nil
+	}
+	other := map[pbTrace.Span_SpanKind][]string{}
+	for kind, opposite := range oppositeKinds {
+		own := map[string]bool{}
+		for _, set := range byKind[kind] {
+			for _, a := range set {
+				own[a] = true
+			}
+		}
+		for _, set := range byKind[opposite] {
+			for _, a := range set {
+				if !own[a] {
+					own[a] = true
+					other[kind] = append(other[kind], a)
+				}
+			}
+		}
+	}
+	return other
+}
+
+func forKind(groups []semconv.Group, kind string) []semconv.Group {
+	if kind == "" {
+		return groups
+	}
+	matched := []semconv.Group{}
+	for _, g := range groups {
+		if g.SpanKind == "" || g.SpanKind == kind {
+			matched = append(matched, g)
+		}
+	}
+	return matched
+}
+
+// sets returns the attribute sets a span of kind has to satisfy one of.
+func (m traceMatch) sets(kind pbTrace.Span_SpanKind) [][]string {
+	if sets, ok := m.byKind[kind]; ok {
+		return sets
+	}
+	return m.groups
 }
 
 func (s *TraceServer) Export(ctx context.Context, req *pbCollectorTrace.ExportTraceServiceRequest) (*pbCollectorTrace.ExportTraceServiceResponse, error) {
@@ -66,72 +346,187 @@ func (s *TraceServer) Export(ctx context.Context, req *pbCollectorTrace.ExportTr
 		return nil, nil
 	}
 	log := slog.With("type", "trace")
-	count := 0
+	if s.preExport != nil {
+		var check bool
+		if log, check = s.preExport(ctx, log, req); !check {
+			return &pbCollectorTrace.ExportTraceServiceResponse{}, nil
+		}
+	}
+	now := time.Now()
+	spans := 0
+	defer func() {
+		s.metrics.observeExport("trace", time.Since(now), spans)
+	}()
+	violations := []Violation{}
+	failedSpans := 0
+	checked := make([]int, len(s.matches))
 	names := []string{}
+	scores := scores{}
 	for _, r := range req.ResourceSpans {
-		if r.SchemaUrl != s.resourceVersion {
-			log.Info("incorrect resource version",
-				slog.String("section", "resource"),
-				slog.String("version", r.SchemaUrl),
-				slog.String("expected", s.resourceVersion),
-			)
+		service := serviceName(r.Resource)
+		if matchesAny(s.exclude, r.GetResource().GetAttributes()) {
+			log.Debug("excluded resource", slog.String("service.name", service))
+			continue
+		}
+		if !s.skipResource {
+			for _, v := range s.checkResourceSpans(ctx, log, r) {
+				v.Time = now
+				v.Service = service
+				v.Signal = "trace"
+				violations = append(violations, v)
+			}
 		}
-		missing, extra := checkResource(s.resourceGroups, s.resourceIgnore, r.Resource)
-		logAttributes(log.With(
-			slog.String("section", "resource"),
-			slog.String("version", r.SchemaUrl),
-		), missing, extra)
 
 		for _, scope := range r.ScopeSpans {
 			log := log.With(slog.String("section", "span"))
-			if scope.SchemaUrl != s.resourceVersion {
-				log.Info("incorrect scope version",
+			if !s.versions.accepts(scope.SchemaUrl) {
+				logVersion(ctx, log, s.scopePolicy, "incorrect scope version",
 					slog.String("schemaUrl", scope.SchemaUrl),
-					slog.String("expected", s.resourceVersion),
+					slog.String("expected", s.versions.String()),
 					slog.Any("scope", scope.Scope),
 				)
-				// count++
+				if s.scopePolicy == PolicyFail {
+					violations = append(violations, Violation{
+						Time:      now,
+						Service:   service,
+						Signal:    "trace",
+						Name:      scope.GetScope().GetName(),
+						Attribute: scope.SchemaUrl,
+						Kind:      ViolationVersion,
+					})
+				}
 			}
 			if scope.Scope != nil {
 				log = log.With(slog.String("scope.name", scope.Scope.Name))
+				s.checkScope(log, scope.Scope)
 			}
-			fmt.Println(len(scope.Spans))
+			spans += len(scope.Spans)
+			skip := s.scopeSkips(scope.GetScope().GetName())
 			for _, span := range scope.Spans {
 				found := false
+				before := len(violations)
 				log := log.With(slog.String("name", span.Name))
-				for _, match := range s.matches {
-					if match.match.MatchString(span.Name) {
-						found = true
-						missing, extra := checkSpan(match.group, match.ignore, span)
-						logAttributes(log, missing, extra)
-						count += len(missing)
-						names = append(names, scope.Scope.Name)
+				vs := newViolations(ViolationLimit, checkLimits(log, s.limits, span.Attributes))
+				if s.misplaced != PolicyIgnore {
+					wrong := misplaced(log, s.placement.resource, "resource", span.Attributes)
+					if s.misplaced == PolicyFail {
+						vs = append(vs, newViolations(ViolationMisplaced, wrong)...)
+					}
+				}
+				if s.statusMessage != PolicyIgnore {
+					wrong := checkStatus(log, span.Status)
+					if s.statusMessage == PolicyFail {
+						vs = append(vs, newViolations(ViolationStatus, wrong)...)
 					}
 				}
+				for _, v := range vs {
+					v.Time = now
+					v.Service = service
+					v.Signal = "trace"
+					v.Name = span.Name
+					violations = append(violations, v)
+				}
+				for _, i := range s.lookup(log, span.Name) {
+					if skip != nil && skip[i] {
+						continue
+					}
+					found = true
+					s.hits[i].Add(1)
+					match := s.matches[i]
+					if checked[i]++; match.maxChecked > 0 && checked[i] > match.maxChecked {
+						continue
+					}
+					log := log
+					if match.advisory {
+						log = log.With(slog.Bool("advisory", true))
+					}
+					vs, required := s.checkMatch(log, match, span, r.GetResource().GetAttributes())
+					scores.add(service, required, countKind(vs, ViolationMissing))
+					for _, v := range vs {
+						v.Time = now
+						v.Service = service
+						v.Signal = "trace"
+						v.Name = span.Name
+						v.Advisory = match.advisory
+						v.Match = match.pattern
+						violations = append(violations, v)
+					}
+					names = append(names, scope.GetScope().GetName())
+				}
 				if !found && s.reportUnmatched {
 					log.Info("unmatched span")
 				}
+				if enforced(violations[before:]) > 0 {
+					failedSpans++
+				}
 			}
 		}
 	}
+	for i, m := range s.matches {
+		if m.maxChecked > 0 && checked[i] > m.maxChecked {
+			log.Info("sampled match",
+				slog.String("match", m.pattern),
+				slog.Int("checked", m.maxChecked),
+				slog.Int("skipped", checked[i]-m.maxChecked),
+			)
+		}
+	}
+	scores.log(log)
+	s.metrics.addScores(scores)
+	if now.Before(s.warmupUntil) {
+		for i := range violations {
+			violations[i].Advisory = true
+		}
+	}
+	for _, sink := range s.sinks {
+		for _, v := range violations {
+			sink.Write(v)
+		}
+	}
+	count := enforced(violations)
+	if advisory := len(violations) - count; advisory > 0 {
+		log.Info("advisory violations", slog.Int("count", advisory))
+	}
+	if count > 0 && s.threshold.tolerates(count, failedSpans, spans) {
+		log.Info("violations within threshold",
+			slog.Int("count", count),
+			slog.Int("failed_spans", failedSpans),
+			slog.Int("spans", spans),
+		)
+		count = 0
+	}
 
 	if s.oneShot {
+		code := 0
 		if count > 0 {
-			os.Exit(100)
+			code = 100
 		}
-		os.Exit(0)
+		s.doneOnce.Do(func() { s.done <- code })
+	}
+	if count > 0 && s.observe.Load() {
+		log.Info("violations observed", slog.Int("count", count))
+		count = 0
 	}
 
+	resp := &pbCollectorTrace.ExportTraceServiceResponse{}
+	var err error
 	if count > 0 {
-		return &pbCollectorTrace.ExportTraceServiceResponse{
-			PartialSuccess: &pbCollectorTrace.ExportTracePartialSuccess{
-				RejectedSpans: int64(count),
-				ErrorMessage:  "missing attributes",
-			},
-		}, status.Error(codes.FailedPrecondition, fmt.Sprintf("missing attributes: %v", names))
+		st := status.New(codes.FailedPrecondition, fmt.Sprintf("missing attributes: %v", names))
+		st = withRetry(withViolations(st, violations), s.grpc)
+		resp.PartialSuccess = &pbCollectorTrace.ExportTracePartialSuccess{
+			RejectedSpans: int64(failedSpans),
+			ErrorMessage:  "missing attributes",
+		}
+		err = st.Err()
+	} else if s.grpc.Summary {
+		resp.PartialSuccess = &pbCollectorTrace.ExportTracePartialSuccess{
+			ErrorMessage: summary(len(violations), enforced(violations), spans, failedSpans),
+		}
 	}
-
-	return &pbCollectorTrace.ExportTraceServiceResponse{}, nil
+	if s.postExport != nil {
+		s.postExport(ctx, ExportResult{Violations: violations, Enforced: count, Err: err})
+	}
+	return resp, err
 }
 
 func filter(input, removed []string) []string {
@@ -148,33 +543,199 @@ OUTER:
 	return output
 }
 
-func checkResource(rg, ignore []string, r *pbResource.Resource) (missing, extra []string) {
-	if r != nil {
-		missing, extra := semconv.Compare(rg, r.Attributes)
-		missing, extra = filter(missing, ignore), filter(extra, ignore)
-		return missing, extra
+// checkMatch checks the span against one of the matches for its name. It
+// returns what was found, only the kind and attribute of the violations are
+// set, and the number of attributes that were required. The resource
+// attributes are only used by resource rules.
+func (s *TraceServer) checkMatch(log *slog.Logger, match traceMatch, span *pbTrace.Span, resource []*pbCommon.KeyValue) ([]Violation, int) {
+	attrs := s.aliases.apply(log, span.Attributes)
+	missing, extra, required := checkSpan(match.sets(span.GetKind()), match.ignore, attrs)
+	log.Debug("matched span",
+		slog.String("match", match.pattern),
+		slog.Any("required", required),
+	)
+	if wrong := presentKeys(attrs, match.otherKind[span.GetKind()]); len(wrong) > 0 {
+		log.Warn("attributes for the other span kind",
+			slog.String("kind", spanKinds[span.GetKind()]),
+			slog.Any("attributes", wrong),
+		)
+	}
+	extra = filterAllowed(extra, match.allowed)
+	if match.extra == PolicyIgnore {
+		extra = nil
+	}
+	s.reporter.logAttributes(log, missing, extra, attrs)
+
+	violations := newViolations(ViolationMissing, missing)
+	if match.extra == PolicyFail {
+		violations = append(violations, newViolations(ViolationExtra, extra)...)
+	}
+	violations = append(violations, newViolations(ViolationRule, checkRules(log, match.rules, attrs, resource))...)
+	violations = append(violations, newViolations(ViolationValue, checkValues(log, s.reporter.redact, match.values, attrs))...)
+	return violations, len(required)
+}
+
+// lookup returns the matches for the name in the order they are checked, by
+// priority, and logs that order when priorities are used.
+func (s *TraceServer) lookup(log *slog.Logger, name string) []int {
+	found := s.matcher.lookup(name)
+	if !s.hasPriority || len(found) < 2 {
+		return found
+	}
+	slices.SortStableFunc(found, func(a, b int) int {
+		return s.matches[b].priority - s.matches[a].priority
+	})
+	patterns := make([]string, len(found))
+	for i, f := range found {
+		patterns[i] = s.matches[f].pattern
+	}
+	log.Debug("match order", slog.Any("matches", patterns))
+	return found
+}
+
+// scopeSkips returns which matches do not apply to spans from the scope, or
+// nil if every match does.
+func (s *TraceServer) scopeSkips(name string) []bool {
+	if !s.hasScope {
+		return nil
+	}
+	skip := make([]bool, len(s.matches))
+	for i, m := range s.matches {
+		skip[i] = m.scope != nil && !m.scope.MatchString(name)
+	}
+	return skip
+}
+
+// Done receives the exit code, 100 if any attributes were missing, once the
+// server has checked its one export in one shot mode.
+func (s *TraceServer) Done() <-chan int {
+	return s.done
+}
+
+// checkResourceSpans logs the resource's problems, and returns a violation if
+// its version is not accepted and the policy is to fail.
+func (s *TraceServer) checkResourceSpans(ctx context.Context, log *slog.Logger, r *pbTrace.ResourceSpans) []Violation {
+	var violations []Violation
+	if !s.checkResourceVersion(ctx, log, r.SchemaUrl) && s.versionPolicy == PolicyFail {
+		violations = append(violations, Violation{Attribute: r.SchemaUrl, Kind: ViolationVersion})
+	}
+	log = log.With(
+		slog.String("section", "resource"),
+		slog.String("version", r.SchemaUrl),
+	)
+	var missing, extra []string
+	attrs := r.GetResource().GetAttributes()
+	if r.Resource != nil {
+		attrs = s.aliases.apply(log, attrs)
+		missing, extra = checkResource(s.resourceGroups, s.resourceIgnore, attrs)
 	}
-	return nil, nil
+	extra = filterAllowed(extra, s.resourceAllowed)
+	if s.resourceExtra == PolicyIgnore {
+		extra = nil
+	}
+	s.reporter.logAttributes(log, missing, extra, attrs)
+	if s.misplaced != PolicyIgnore {
+		wrong := misplaced(log, s.placement.span, "span", attrs)
+		if s.misplaced == PolicyFail {
+			violations = append(violations, newViolations(ViolationMisplaced, wrong)...)
+		}
+	}
+	return violations
 }
 
-func checkSpan(ag, ignore []string, s *pbTrace.Span) (missing []string, extra []string) {
-	if s != nil {
-		missing, extra := semconv.Compare(ag, s.Attributes)
-		missing, extra = filter(missing, ignore), filter(extra, ignore)
-		return missing, extra
+// checkScope logs the scope's missing and extra attributes, if any groups are
+// configured for scopes.
+func (s *TraceServer) checkScope(log *slog.Logger, scope *pbCommon.InstrumentationScope) {
+	if len(s.scopeGroups) == 0 {
+		return
+	}
+	missing, extra := checkResource(s.scopeGroups, s.scopeIgnore, scope.Attributes)
+	extra = filterAllowed(extra, s.scopeAllowed)
+	if s.scopeExtra == PolicyIgnore {
+		extra = nil
 	}
-	return nil, nil
+	s.reporter.logAttributes(log.With(slog.String("section", "scope")), missing, extra, scope.Attributes)
 }
 
-func logAttributes(log *slog.Logger, missing, extra []string) {
-	if len(missing) > 0 {
-		log.Info("missing attributes",
-			slog.Any("attributes", missing),
+// checkResourceVersion logs a missing or unaccepted schema url, and reports
+// whether it was accepted.
+func (s *TraceServer) checkResourceVersion(ctx context.Context, log *slog.Logger, url string) bool {
+	log = log.With(slog.String("section", "resource"))
+	if url == "" && s.reportMissing {
+		log.Log(ctx, s.missingLevel, "missing resource schema url",
+			slog.String("expected", s.versions.String()),
 		)
+		return false
 	}
-	if len(extra) > 0 {
-		log.Info("extra attributes",
-			slog.Any("attributes", extra),
+	if s.checkGroups && url != "" && !sameMinor(url, s.groupsVersion) {
+		log.Warn("schema url does not match the semantic conventions",
+			slog.String("version", url),
+			slog.String("semconv", s.groupsVersion),
 		)
 	}
+	if !s.versions.accepts(url) {
+		logVersion(ctx, log, s.versionPolicy, "incorrect resource version",
+			slog.String("version", url),
+			slog.String("expected", s.versions.String()),
+		)
+		return false
+	}
+	return true
+}
+
+func logVersion(ctx context.Context, log *slog.Logger, policy Policy, msg string, attrs ...any) {
+	switch policy {
+	case PolicyIgnore:
+	case PolicyWarn, PolicyFail:
+		log.Warn(msg, attrs...)
+	default:
+		log.Info(msg, attrs...)
+	}
+}
+
+// filterAllowed removes the allowed attributes from extra. An allowed entry
+// ending in ".*" allows every attribute in that namespace.
+func filterAllowed(extra, allowed []string) []string {
+	if len(allowed) == 0 {
+		return extra
+	}
+	output := []string{}
+OUTER:
+	for _, e := range extra {
+		for _, a := range allowed {
+			if e == a {
+				continue OUTER
+			}
+			if ns, ok := strings.CutSuffix(a, "*"); ok && strings.HasSuffix(ns, ".") && strings.HasPrefix(e, ns) {
+				continue OUTER
+			}
+		}
+		output = append(output, e)
+	}
+	return output
+}
+
+func checkResource(rg, ignore []string, attrs []*pbCommon.KeyValue) (missing, extra []string) {
+	missing, extra = semconv.Compare(rg, attrs)
+	return filter(missing, ignore), filter(extra, ignore)
+}
+
+// checkSpan also returns the attributes required of the span, from the
+// closest of the attribute sets.
+func checkSpan(ag [][]string, ignore []string, attrs []*pbCommon.KeyValue) (missing, extra, required []string) {
+	// Ignored attributes are removed from the sets first, so they do not
+	// decide which set is the closest.
+	sets := ag
+	if len(ignore) > 0 {
+		sets = make([][]string, len(ag))
+		for i, set := range ag {
+			sets[i] = filter(set, ignore)
+		}
+	}
+	missing, extra, best := semconv.CompareAny(sets, attrs)
+	extra = filter(extra, ignore)
+	if best >= 0 {
+		required = sets[best]
+	}
+	return missing, extra, required
 }
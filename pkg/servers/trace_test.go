@@ -0,0 +1,299 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestFilterAllowed(t *testing.T) {
+	extra := []string{"acme.team", "acme.cost.center", "acmecorp.id", "http.method"}
+	tests := []struct {
+		name    string
+		allowed []string
+		want    []string
+	}{
+		{
+			name: "None allowed",
+			want: extra,
+		},
+		{
+			name:    "Exact",
+			allowed: []string{"http.method"},
+			want:    []string{"acme.team", "acme.cost.center", "acmecorp.id"},
+		},
+		{
+			name:    "Namespace",
+			allowed: []string{"acme.*"},
+			want:    []string{"acmecorp.id", "http.method"},
+		},
+		{
+			name:    "Not a namespace",
+			allowed: []string{"acme*"},
+			want:    extra,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, filterAllowed(extra, tt.allowed))
+		})
+	}
+}
+
+func TestMatchSetsByKind(t *testing.T) {
+	g, err := semconv.ParseGroups()
+	require.NoError(t, err)
+	groups := []semconv.Group{g["trace.http.client"], g["trace.http.server"]}
+	m := traceMatch{
+		groups: attributeSets(g, groups, nil, ""),
+		byKind: kindAttributeSets(g, groups, nil),
+	}
+
+	client := m.sets(pbTrace.Span_SPAN_KIND_CLIENT)
+	require.Len(t, client, 1)
+	assert.Contains(t, client[0], "http.resend_count")
+	assert.NotContains(t, client[0], "http.route")
+
+	server := m.sets(pbTrace.Span_SPAN_KIND_SERVER)
+	require.Len(t, server, 1)
+	assert.Contains(t, server[0], "http.route")
+	assert.NotContains(t, server[0], "http.resend_count")
+
+	unspecified := m.sets(pbTrace.Span_SPAN_KIND_UNSPECIFIED)
+	require.Len(t, unspecified, 1)
+	assert.Contains(t, unspecified[0], "http.route")
+	assert.Contains(t, unspecified[0], "http.resend_count")
+
+	assert.Nil(t, kindAttributeSets(g, []semconv.Group{g["host"]}, nil))
+
+	other := otherKindAttributes(m.byKind)
+	assert.Contains(t, other[pbTrace.Span_SPAN_KIND_SERVER], "http.resend_count")
+	assert.NotContains(t, other[pbTrace.Span_SPAN_KIND_SERVER], "http.route")
+	assert.NotContains(t, other[pbTrace.Span_SPAN_KIND_SERVER], "http.request.method")
+	assert.Contains(t, other[pbTrace.Span_SPAN_KIND_CLIENT], "http.route")
+}
+
+func TestCheckSpanAnyOfIgnore(t *testing.T) {
+	sets := [][]string{{"rpc.system", "rpc.service"}, {"http.request.method"}}
+	missing, extra, required := checkSpan(sets, []string{"rpc.system", "rpc.service"}, []*pbCommon.KeyValue{stringKV("url.path", "/")})
+	assert.Empty(t, missing)
+	assert.Equal(t, []string{"url.path"}, extra)
+	assert.Empty(t, required)
+}
+
+func TestCoverage(t *testing.T) {
+	s := newTraceService(t, acmeConfig(Match{Match: "^GET "}, Match{Match: "^POST "}), acmeGroups())
+	_, err := exportSpans(s, &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "GET /users"})
+	require.NoError(t, err)
+	assert.Equal(t, []Coverage{{Match: "^GET ", Hits: 2}, {Match: "^POST ", Hits: 0}}, s.Coverage())
+}
+
+func TestWarmup(t *testing.T) {
+	cfg := acmeConfig(Match{Match: ".*", Groups: []string{"acme"}})
+	_, err := exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"})
+	assert.Error(t, err)
+
+	cfg.Warmup = time.Hour
+	_, err = exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"})
+	assert.NoError(t, err)
+
+	cfg.Warmup = 50 * time.Millisecond
+	s := newTraceService(t, cfg, acmeGroups())
+	time.Sleep(cfg.Warmup)
+	s.StartWarmup()
+	_, err = exportSpans(s, &pbTrace.Span{Name: "GET /"})
+	assert.NoError(t, err)
+}
+
+func newTraceService(t *testing.T, cfg Config, g map[string]semconv.Group, opts ...Option) *TraceServer {
+	t.Helper()
+	s, err := NewTraceService(cfg, g, opts...)
+	require.NoError(t, err)
+	return s
+}
+
+func TestNewTraceServiceInvalid(t *testing.T) {
+	_, err := NewTraceService(Config{Trace: []Match{{Match: "^GET", Extra: "drop"}}}, map[string]semconv.Group{})
+	assert.ErrorContains(t, err, `trace match "^GET": extra: unknown policy "drop"`)
+}
+
+// acmeGroups has a single group, acme, of the one attribute acme.team.
+func acmeGroups() map[string]semconv.Group {
+	return map[string]semconv.Group{
+		"acme": {Id: "acme", Attributes: []semconv.Attribute{{CanonicalId: "acme.team"}}},
+	}
+}
+
+// acmeConfig checks spans against the matches, without the resource.
+func acmeConfig(matches ...Match) Config {
+	return Config{
+		Signals: Signals{Trace: SignalConfig{SkipResource: true}},
+		Trace:   matches,
+	}
+}
+
+func exportSpans(s *TraceServer, spans ...*pbTrace.Span) (*pbCollectorTrace.ExportTraceServiceResponse, error) {
+	return s.Export(context.Background(), &pbCollectorTrace.ExportTraceServiceRequest{
+		ResourceSpans: []*pbTrace.ResourceSpans{{
+			ScopeSpans: []*pbTrace.ScopeSpans{{
+				Scope: &pbCommon.InstrumentationScope{Name: "test"},
+				Spans: spans,
+			}},
+		}},
+	})
+}
+
+func TestScopeMatch(t *testing.T) {
+	s := newTraceService(t, acmeConfig(Match{Scope: "^te"}, Match{Match: "^GET ", Scope: "^other"}, Match{Match: "^GET "}), acmeGroups())
+	_, err := exportSpans(s, &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "POST /"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 0, 1}, []uint64{s.Coverage()[0].Hits, s.Coverage()[1].Hits, s.Coverage()[2].Hits})
+
+	_, err = s.Export(context.Background(), &pbCollectorTrace.ExportTraceServiceRequest{
+		ResourceSpans: []*pbTrace.ResourceSpans{{
+			ScopeSpans: []*pbTrace.ScopeSpans{{Spans: []*pbTrace.Span{{Name: "GET /"}}}},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), s.Coverage()[2].Hits)
+}
+
+func TestHooks(t *testing.T) {
+	cfg := acmeConfig(Match{Match: ".*", Groups: []string{"acme"}})
+	check := true
+	var result ExportResult
+	s := newTraceService(t, cfg, acmeGroups(),
+		WithPreExport(func(ctx context.Context, log *slog.Logger, req *pbCollectorTrace.ExportTraceServiceRequest) (*slog.Logger, bool) {
+			return log, check
+		}),
+		WithPostExport(func(ctx context.Context, r ExportResult) {
+			result = r
+		}),
+	)
+
+	_, err := exportSpans(s, &pbTrace.Span{Name: "GET /"})
+	assert.Error(t, err)
+	assert.Equal(t, 1, result.Enforced)
+	assert.Equal(t, err, result.Err)
+
+	check = false
+	_, err = exportSpans(s, &pbTrace.Span{Name: "GET /"})
+	assert.NoError(t, err)
+}
+
+func TestThreshold(t *testing.T) {
+	assert.False(t, Threshold{}.tolerates(1, 1, 10))
+	assert.True(t, Threshold{Count: 2}.tolerates(2, 1, 10))
+	assert.False(t, Threshold{Count: 2}.tolerates(3, 1, 10))
+	assert.True(t, Threshold{Percent: 10}.tolerates(5, 1, 10))
+	assert.False(t, Threshold{Percent: 10}.tolerates(5, 2, 10))
+
+	cfg := acmeConfig(Match{Match: "^GET ", Groups: []string{"acme"}})
+	cfg.Threshold = Threshold{Percent: 50}
+	_, err := exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "POST /"})
+	assert.NoError(t, err)
+	_, err = exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "GET /users"})
+	assert.Error(t, err)
+}
+
+func TestRejectedSpans(t *testing.T) {
+	g := acmeGroups()
+	g["acme"] = semconv.Group{Id: "acme", Attributes: []semconv.Attribute{{CanonicalId: "acme.team"}, {CanonicalId: "acme.owner"}}}
+	cfg := acmeConfig(Match{Match: "^GET ", Groups: []string{"acme"}})
+	resp, err := exportSpans(newTraceService(t, cfg, g), &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "POST /"})
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), resp.GetPartialSuccess().GetRejectedSpans())
+
+	cfg.Threshold = Threshold{Percent: 50}
+	cfg.GRPC.Summary = true
+	resp, err = exportSpans(newTraceService(t, cfg, g), &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "POST /"})
+	require.NoError(t, err)
+	assert.Equal(t, "violations=2;advisory=0;spans=2;failed_spans=1", resp.GetPartialSuccess().GetErrorMessage())
+}
+
+func TestMaxChecked(t *testing.T) {
+	var result ExportResult
+	s := newTraceService(t, acmeConfig(Match{Match: ".*", Groups: []string{"acme"}, MaxChecked: 2}), acmeGroups(), WithPostExport(func(ctx context.Context, r ExportResult) { result = r }))
+	_, err := exportSpans(s, &pbTrace.Span{Name: "a"}, &pbTrace.Span{Name: "b"}, &pbTrace.Span{Name: "c"})
+	assert.Error(t, err)
+	assert.Equal(t, 2, result.Enforced)
+}
+
+func TestScopeMismatch(t *testing.T) {
+	cfg := acmeConfig()
+	cfg.Schema = SchemaConfig{URL: "https://opentelemetry.io/schemas/1.21.0", ScopeMismatch: PolicyFail}
+	_, err := exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"})
+	assert.Error(t, err)
+
+	cfg.Schema.ScopeMismatch = PolicyWarn
+	_, err = exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"})
+	assert.NoError(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	s := newTraceService(t, Config{Trace: []Match{{Match: "^job", Groups: []string{"acme"}}}}, acmeGroups())
+	violations := s.Check(slog.Default(),
+		Item{Service: "batch", Name: "job.run"},
+		Item{Service: "batch", Name: "job.run", Attributes: []*pbCommon.KeyValue{stringKV("acme.team", "data")}},
+		Item{Service: "batch", Name: "other"},
+	)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "acme.team", violations[0].Attribute)
+	assert.Equal(t, "batch", violations[0].Service)
+}
+
+func TestSummary(t *testing.T) {
+	cfg := acmeConfig(Match{Match: ".*", Groups: []string{"acme"}, Advisory: true})
+	cfg.GRPC.Summary = true
+	resp, err := exportSpans(newTraceService(t, cfg, acmeGroups()), &pbTrace.Span{Name: "GET /"}, &pbTrace.Span{Name: "GET /"})
+	require.NoError(t, err)
+	assert.Equal(t, "violations=0;advisory=2;spans=2;failed_spans=0", resp.GetPartialSuccess().GetErrorMessage())
+	assert.Zero(t, resp.GetPartialSuccess().GetRejectedSpans())
+}
+
+func TestPriority(t *testing.T) {
+	g := acmeGroups()
+	g["http"] = semconv.Group{Id: "http", Attributes: []semconv.Attribute{{CanonicalId: "http.route"}}}
+	s := newTraceService(t, Config{Trace: []Match{
+		{Match: ".*", Groups: []string{"acme"}},
+		{Match: "^GET ", Groups: []string{"http"}, Priority: 10},
+	}}, g)
+	assert.Equal(t, []int{1, 0}, s.lookup(slog.Default(), "GET /"))
+
+	violations := s.Check(slog.Default(), Item{Name: "GET /"})
+	require.Len(t, violations, 2)
+	assert.Equal(t, "http.route", violations[0].Attribute)
+	assert.Equal(t, "^GET ", violations[0].Match)
+	assert.Equal(t, ".*", violations[1].Match)
+}
+
+func TestScopeAttributes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, nil)))
+
+	cfg := acmeConfig()
+	cfg.ScopeAttributes = Match{Groups: []string{"acme"}}
+	s := newTraceService(t, cfg, acmeGroups())
+	_, err := s.Export(context.Background(), &pbCollectorTrace.ExportTraceServiceRequest{
+		ResourceSpans: []*pbTrace.ResourceSpans{{
+			ScopeSpans: []*pbTrace.ScopeSpans{{
+				Scope: &pbCommon.InstrumentationScope{Name: "test", Attributes: []*pbCommon.KeyValue{stringKV("acme.owner", "me")}},
+				Spans: []*pbTrace.Span{{Name: "GET /"}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+	out := buf.String()
+	assert.Regexp(t, `msg="missing attributes".*section=scope.*attributes=\[acme.team\]`, out)
+	assert.Regexp(t, `msg="extra attributes".*section=scope.*attributes=\[acme.owner\]`, out)
+}
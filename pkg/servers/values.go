@@ -0,0 +1,81 @@
+package servers
+
+import (
+	"log/slog"
+	"regexp"
+
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+type expectation struct {
+	Expected
+	pattern *regexp.Regexp
+}
+
+func newExpectations(cfg []Expected) []expectation {
+	expected := []expectation{}
+	for _, e := range cfg {
+		ex := expectation{Expected: e}
+		if e.Pattern != "" {
+			ex.pattern = regexp.MustCompile(e.Pattern)
+		}
+		expected = append(expected, ex)
+	}
+	return expected
+}
+
+// checkValues logs each attribute that does not have its expected value, or
+// does not match its pattern, and returns them.
+func checkValues(log *slog.Logger, r redactor, expected []expectation, attributes []*pbCommon.KeyValue) []string {
+	mismatched := []string{}
+	for _, e := range expected {
+		for _, kv := range attributes {
+			if kv.Key != e.Key {
+				continue
+			}
+			got := valueString(kv.Value)
+			if e.pattern != nil && !e.pattern.MatchString(got) {
+				log.Info("value does not match pattern",
+					slog.String("attribute", e.Key),
+					slog.String("pattern", e.Pattern),
+					slog.String("got", r.redact(e.Key, got)),
+				)
+				mismatched = append(mismatched, e.Key)
+			} else if e.pattern == nil && got != e.Value {
+				log.Info("value mismatch",
+					slog.String("attribute", e.Key),
+					slog.String("expected", r.redact(e.Key, e.Value)),
+					slog.String("got", r.redact(e.Key, got)),
+				)
+				mismatched = append(mismatched, e.Key)
+			}
+			break
+		}
+	}
+	return mismatched
+}
+
+// matches reports whether the attribute is present and has the value, or
+// matches the pattern.
+func (e expectation) matches(attributes []*pbCommon.KeyValue) bool {
+	for _, kv := range attributes {
+		if kv.Key != e.Key {
+			continue
+		}
+		got := valueString(kv.Value)
+		if e.pattern != nil {
+			return e.pattern.MatchString(got)
+		}
+		return got == e.Value
+	}
+	return false
+}
+
+func matchesAny(expected []expectation, attributes []*pbCommon.KeyValue) bool {
+	for _, e := range expected {
+		if e.matches(attributes) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package servers
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestCheckValues(t *testing.T) {
+	expected := newExpectations([]Expected{
+		{Key: "deployment.environment", Value: "production"},
+		{Key: "service.version", Pattern: `^\d+\.\d+\.\d+$`},
+		{Key: "k8s.namespace.name", Pattern: `^team-`},
+	})
+	attrs := []*pbCommon.KeyValue{
+		stringKV("deployment.environment", "production"),
+		stringKV("service.version", "1.2"),
+		stringKV("k8s.namespace.name", "team-payments"),
+	}
+	assert.Equal(t, []string{"service.version"}, checkValues(slog.Default(), newRedactor(Redact{}), expected, attrs))
+}
+
+func TestMatchesAny(t *testing.T) {
+	exclude := newExpectations([]Expected{
+		{Key: "service.name", Value: "otelcol"},
+		{Key: "service.name", Pattern: `^internal-`},
+	})
+	assert.True(t, matchesAny(exclude, []*pbCommon.KeyValue{stringKV("service.name", "otelcol")}))
+	assert.True(t, matchesAny(exclude, []*pbCommon.KeyValue{stringKV("service.name", "internal-billing")}))
+	assert.False(t, matchesAny(exclude, []*pbCommon.KeyValue{stringKV("service.name", "checkout")}))
+	assert.False(t, matchesAny(exclude, nil))
+}
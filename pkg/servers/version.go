@@ -0,0 +1,138 @@
+package servers
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type version [3]int
+
+// parseVersion parses a version like "1.24.0", or the one at the end of an
+// OTEL schema url.
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(path.Base(s), "v")
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version{}, false
+	}
+	v := version{}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return version{}, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func (v version) compare(o version) int {
+	for i := range v {
+		if v[i] != o[i] {
+			if v[i] < o[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// sameMinor reports whether two schema urls have the same major and minor
+// version, patch releases do not change the conventions.
+func sameMinor(a, b string) bool {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	return okA && okB && va[0] == vb[0] && va[1] == vb[1]
+}
+
+type constraint struct {
+	op string
+	v  version
+}
+
+func (c constraint) accepts(v version) bool {
+	cmp := v.compare(c.v)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
+
+// versionCheck decides whether a schema url has an accepted version. Without
+// ranges only the expected url is accepted.
+type versionCheck struct {
+	expected string
+	ranges   [][]constraint
+}
+
+func newVersionCheck(expected string, entries []string) (versionCheck, error) {
+	vc := versionCheck{expected: expected}
+	for _, entry := range entries {
+		r := []constraint{}
+		for _, field := range strings.Fields(entry) {
+			op := strings.TrimRight(field, "0123456789.v")
+			v, ok := parseVersion(strings.TrimPrefix(field, op))
+			if !ok || !validOp(op) {
+				return versionCheck{}, fmt.Errorf("invalid schema version %q", entry)
+			}
+			r = append(r, constraint{op: op, v: v})
+		}
+		vc.ranges = append(vc.ranges, r)
+	}
+	return vc, nil
+}
+
+func validOp(op string) bool {
+	switch op {
+	case "", "=", ">=", ">", "<=", "<", "!=":
+		return true
+	}
+	return false
+}
+
+func (vc versionCheck) accepts(url string) bool {
+	if len(vc.ranges) == 0 {
+		return url == vc.expected
+	}
+	v, ok := parseVersion(url)
+	if !ok {
+		return false
+	}
+OUTER:
+	for _, r := range vc.ranges {
+		for _, c := range r {
+			if !c.accepts(v) {
+				continue OUTER
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (vc versionCheck) String() string {
+	if len(vc.ranges) == 0 {
+		return vc.expected
+	}
+	parts := []string{}
+	for _, r := range vc.ranges {
+		cs := []string{}
+		for _, c := range r {
+			cs = append(cs, fmt.Sprintf("%s%d.%d.%d", c.op, c.v[0], c.v[1], c.v[2]))
+		}
+		parts = append(parts, strings.Join(cs, " "))
+	}
+	return strings.Join(parts, " || ")
+}
@@ -0,0 +1,36 @@
+package servers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCheck(t *testing.T) {
+	exact, err := newVersionCheck("https://opentelemetry.io/schemas/1.24.0", nil)
+	require.NoError(t, err)
+	assert.True(t, exact.accepts("https://opentelemetry.io/schemas/1.24.0"))
+	assert.False(t, exact.accepts("https://opentelemetry.io/schemas/1.25.0"))
+
+	vc, err := newVersionCheck("", []string{">=1.24.0 <1.28.0", "1.20"})
+	require.NoError(t, err)
+	assert.True(t, vc.accepts("https://opentelemetry.io/schemas/1.24.0"))
+	assert.True(t, vc.accepts("https://opentelemetry.io/schemas/1.27.1"))
+	assert.True(t, vc.accepts("https://opentelemetry.io/schemas/1.20.0"))
+	assert.False(t, vc.accepts("https://opentelemetry.io/schemas/1.28.0"))
+	assert.False(t, vc.accepts("https://opentelemetry.io/schemas/1.21.0"))
+	assert.False(t, vc.accepts(""))
+	assert.Equal(t, ">=1.24.0 <1.28.0 || 1.20.0", vc.String())
+
+	_, err = newVersionCheck("", []string{"~1.2"})
+	assert.EqualError(t, err, `invalid schema version "~1.2"`)
+	err = Config{Schema: SchemaConfig{Versions: []string{"~1.2"}}}.Validate(nil)
+	assert.EqualError(t, err, `schema.versions: invalid schema version "~1.2"`)
+}
+
+func TestSameMinor(t *testing.T) {
+	assert.True(t, sameMinor("https://opentelemetry.io/schemas/1.21.0", "https://opentelemetry.io/schemas/1.21.1"))
+	assert.False(t, sameMinor("https://opentelemetry.io/schemas/1.20.0", "https://opentelemetry.io/schemas/1.27.0"))
+	assert.False(t, sameMinor("", "https://opentelemetry.io/schemas/1.21.0"))
+}
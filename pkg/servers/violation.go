@@ -0,0 +1,80 @@
+package servers
+
+import "time"
+
+// Violation is a single problem, that counts against an export, found in the
+// telemetry.
+type Violation struct {
+	Time      time.Time `json:"time"`
+	Service   string    `json:"service"`
+	Signal    string    `json:"signal"`
+	Name      string    `json:"name"`
+	Attribute string    `json:"attribute"`
+	Kind      string    `json:"kind"`
+	// Match is the pattern of the match that found the violation, if any.
+	Match    string `json:"match,omitempty"`
+	Advisory bool   `json:"advisory,omitempty"`
+}
+
+const (
+	ViolationMissing   = "missing"
+	ViolationExtra     = "extra"
+	ViolationRule      = "rule"
+	ViolationValue     = "value"
+	ViolationVersion   = "version"
+	ViolationLimit     = "limit"
+	ViolationMisplaced = "misplaced"
+	ViolationStatus    = "status"
+)
+
+func newViolations(kind string, attributes []string) []Violation {
+	violations := make([]Violation, 0, len(attributes))
+	for _, a := range attributes {
+		violations = append(violations, Violation{Kind: kind, Attribute: a})
+	}
+	return violations
+}
+
+// enforced returns the number of violations that are not advisory.
+func enforced(violations []Violation) int {
+	count := 0
+	for _, v := range violations {
+		if !v.Advisory {
+			count++
+		}
+	}
+	return count
+}
+
+func countKind(violations []Violation, kind string) int {
+	count := 0
+	for _, v := range violations {
+		if v.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Sink receives every violation found by a server. Write is called from the
+// export and must not block.
+type Sink interface {
+	Write(Violation)
+}
+
+type Option func(*TraceServer)
+
+// WithGroupsVersion sets the schema url of the semantic conventions the groups
+// came from, when they are not the compiled in ones.
+func WithGroupsVersion(url string) Option {
+	return func(s *TraceServer) {
+		s.groupsVersion = url
+	}
+}
+
+// WithSink adds a sink, every sink receives every violation.
+func WithSink(sink Sink) Option {
+	return func(s *TraceServer) {
+		s.sinks = append(s.sinks, sink)
+	}
+}